@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"peronal_finance_cli_manager/internal/archive"
+	"peronal_finance_cli_manager/internal/db"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage:\n  archive dump <file.json>\n  archive restore <file.json>\n")
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command, path := flag.Arg(0), flag.Arg(1)
+
+	db.Connect()
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	switch command {
+	case "dump":
+		a, err := archive.Dump(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Dumped %d categories and %d transactions to %s\n", len(a.Categories), len(a.Transactions), path)
+
+	case "restore":
+		a, err := archive.Restore(path)
+		var invalid *archive.ValidationError
+		if errors.As(err, &invalid) {
+			fmt.Println(invalid.Error())
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Restored %d categories and %d transactions from %s\n", len(a.Categories), len(a.Transactions), path)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
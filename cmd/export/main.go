@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/export"
+	"peronal_finance_cli_manager/internal/models"
+	"peronal_finance_cli_manager/internal/transaction"
+	"strings"
+	"time"
+)
+
+func main() {
+	format := flag.String("format", "csv", "export format: "+strings.Join(export.Formats(), ", "))
+	output := flag.String("o", "", "file to write to (default stdout)")
+	currency := flag.String("currency", "", "currency written after every amount")
+	columns := flag.String("columns", strings.Join(export.DefaultColumns, ","), "CSV columns to export")
+	category := flag.String("category", "", "only export transactions of this category")
+	from := flag.String("from", "", "only export transactions on or after this date (YYYY-MM-DD)")
+	to := flag.String("to", "", "only export transactions on or before this date (YYYY-MM-DD)")
+	flag.Parse()
+
+	write, err := export.Lookup(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := export.Options{Currency: *currency}
+	if opts.Columns, err = export.ParseColumns(*columns); err != nil {
+		log.Fatal(err)
+	}
+	fromDate, err := parseDate(*from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	toDate, err := parseDate(*to)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db.Connect()
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	categories, err := db.GetAllCategories()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var txs []models.Transaction
+	if *category != "" {
+		cat, err := db.GetCategoryByName(*category)
+		if err != nil {
+			log.Fatalf("category '%s' not found", *category)
+		}
+		txs, err = db.GetTransactionsByCategory(cat.ID)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else if txs, err = db.GetAllTransactions(); err != nil {
+		log.Fatal(err)
+	}
+	txs = transaction.FilterBetween(txs, fromDate, toDate)
+
+	if *output == "" {
+		if err := write(os.Stdout, categories, txs, opts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := export.WriteFile(*output, *format, categories, txs, opts); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Exported %d transactions to %s\n", len(txs), *output)
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s' (YYYY-MM-DD)", value)
+	}
+	return date, nil
+}
@@ -2,8 +2,9 @@ package main
 
 import (
 	"log"
+	"path/filepath"
 	"peronal_finance_cli_manager/internal/db"
-	"peronal_finance_cli_manager/internal/models"
+	"peronal_finance_cli_manager/internal/importer"
 	"peronal_finance_cli_manager/internal/ui"
 
 	_ "github.com/charmbracelet/bubbletea"
@@ -13,11 +14,15 @@ import (
 func main() {
 	db.Connect()
 
-	err := db.DB.AutoMigrate(&models.Category{}, &models.Transaction{})
+	err := db.Migrate()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if err := importer.LoadProfiles(filepath.Join(db.DataDir, "profiles.json")); err != nil {
+		log.Fatal(err)
+	}
+
 	menu := ui.NewMenuModel()
 
 	p := tea.NewProgram(menu)
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func main() {
+	category := flag.String("category", "", "only check transactions of this category")
+	from := flag.String("from", "", "only check transactions on or after this date (YYYY-MM-DD)")
+	to := flag.String("to", "", "only check transactions on or before this date (YYYY-MM-DD)")
+	skip := flag.String("skip", "", "comma separated transaction ids to leave where they are")
+	apply := flag.Bool("apply", false, "move the transactions instead of only showing the diff")
+	showLog := flag.Bool("log", false, "show the audit log and exit")
+	flag.Parse()
+
+	scope := db.RecategorizeScope{CategoryName: *category}
+	var err error
+	if scope.From, err = parseDate(*from); err != nil {
+		log.Fatal(err)
+	}
+	if scope.To, err = parseDate(*to); err != nil {
+		log.Fatal(err)
+	}
+	skipped, err := parseIDs(*skip)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db.Connect()
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *showLog {
+		printAuditLog()
+		return
+	}
+
+	planned, err := db.PlanRecategorization(scope)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var moves []models.CategoryMove
+	for _, move := range planned {
+		mark := "  "
+		if skipped[move.Transaction.ID] {
+			mark = "- "
+		} else {
+			moves = append(moves, move)
+		}
+		tx := move.Transaction
+		fmt.Printf("%s#%-6d %s %10.2f  %-24s %s -> %s\n",
+			mark, tx.ID, tx.Date.Format("2006-01-02"), tx.Amount, tx.Payee, move.From.Name, move.To.Name)
+	}
+	if len(planned) == 0 {
+		fmt.Println("The rules would not move any transaction")
+		return
+	}
+
+	if !*apply {
+		fmt.Printf("%d transaction(s) would move; run again with -apply to move them\n", len(moves))
+		return
+	}
+	moved, err := db.ApplyRecategorization(moves)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Moved %d transaction(s)\n", moved)
+}
+
+func printAuditLog() {
+	entries, err := db.GetAuditEntries()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, entry := range entries {
+		fmt.Printf("%s  %-13s %s\n", entry.CreatedAt.Format("2006-01-02 15:04"), entry.Action, entry.Summary)
+		for _, c := range entry.Changes {
+			fmt.Printf("    #%-6d %-9s %s -> %s\n", c.TransactionID, c.Field, c.From, c.To)
+		}
+	}
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s' (YYYY-MM-DD)", value)
+	}
+	return date, nil
+}
+
+func parseIDs(value string) (map[uint]bool, error) {
+	ids := make(map[uint]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(part), "#"))
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction id '%s'", part)
+		}
+		ids[uint(id)] = true
+	}
+	return ids, nil
+}
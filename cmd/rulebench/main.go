@@ -0,0 +1,156 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"peronal_finance_cli_manager/internal/rules"
+	"strings"
+)
+
+func main() {
+	text := flag.String("text", "", "payee or description to test the rules on")
+	amount := flag.Float64("amount", 0, "amount of the test transaction")
+	id := flag.Uint("id", 0, "test the rules on this stored transaction instead")
+	report := flag.Bool("report", false, "report rules that never match the stored transactions or are shadowed")
+	flag.Parse()
+
+	if *text == "" && *id == 0 && !*report {
+		flag.Usage()
+		return
+	}
+
+	db.Connect()
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	list, err := db.GetCategoryRules()
+	if err != nil {
+		log.Fatal(err)
+	}
+	labels := make(map[uint]string, len(list))
+	for _, rule := range list {
+		labels[rule.ID] = label(rule)
+	}
+	names := func(ids ...uint) string {
+		parts := make([]string, 0, len(ids))
+		for _, id := range ids {
+			parts = append(parts, labels[id])
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	if *report {
+		printReport(names)
+		return
+	}
+
+	tx := models.Transaction{Payee: *text, Description: *text, Amount: float32(*amount)}
+	if *id != 0 {
+		stored, err := db.GetTransaction(*id)
+		if err != nil {
+			log.Fatalf("transaction #%d not found", *id)
+		}
+		tx = *stored
+		fmt.Printf("#%d %s %.2f  %s | %s (%s)\n\n",
+			tx.ID, tx.Date.Format("2006-01-02"), tx.Amount, tx.Payee, tx.Description, tx.Category.Name)
+	}
+
+	steps, res, err := db.ExplainRules(tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, step := range steps {
+		fmt.Printf("%-4d %-20s %s\n", step.Rule.Priority, label(step.Rule), outcome(step, names))
+	}
+
+	fmt.Println()
+	if len(res.Matched) == 0 {
+		fmt.Println("No rule matches")
+		return
+	}
+	printResult(res, names)
+}
+
+// outcome tells how a rule fared against the test transaction
+func outcome(step rules.Step, names func(...uint) string) string {
+	switch {
+	case step.Err != nil:
+		return "broken: " + step.Err.Error()
+	case !step.Matched && !step.Rule.Enabled:
+		return "disabled"
+	case !step.Matched:
+		return "no match"
+	case !step.Rule.Enabled:
+		return "disabled, would match"
+	case step.StoppedBy != 0:
+		return "matches, not reached: stopped by " + names(step.StoppedBy)
+	}
+
+	line := "matched"
+	if len(step.Groups) > 0 {
+		line += " [" + strings.Join(step.Groups, " ") + "]"
+	}
+	if len(step.Effects) > 0 {
+		line += " -> " + strings.Join(step.Effects, "; ")
+	}
+	if len(step.DecidedBy) > 0 {
+		line += " (rest decided by " + names(step.DecidedBy...) + ")"
+	}
+	if len(step.Effects) == 0 {
+		line += ", no effect"
+	}
+	return line
+}
+
+func printResult(res rules.Result, names func(...uint) string) {
+	out := res.Transaction
+	if out.Category.Name != "" {
+		fmt.Println("category:", out.Category.Name)
+	}
+	for _, part := range res.Splits {
+		fmt.Printf("split:    %.2f to %s\n", part.Amount, part.Category.Name)
+	}
+	fmt.Println("payee:   ", out.Payee)
+	if out.Tags != "" {
+		fmt.Println("tags:    ", out.Tags)
+	}
+	if out.IsTransfer {
+		fmt.Println("transfer: yes")
+	}
+	if res.Skip {
+		fmt.Println("skipped on import")
+	}
+	fmt.Println("matched: ", names(res.Matched...))
+}
+
+func printReport(names func(...uint) string) {
+	report, err := db.AnalyzeRules()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%-4s %-20s %8s %8s  %s\n", "Prio", "Name", "Matched", "Changed", "Finding")
+	for _, c := range report {
+		finding := ""
+		switch {
+		case c.Never():
+			finding = "never matches"
+		case c.Shadowed():
+			finding = "shadowed by " + names(c.ShadowedBy...)
+		case len(c.ShadowedBy) > 0:
+			finding = "partly shadowed by " + names(c.ShadowedBy...)
+		}
+		fmt.Printf("%-4d %-20s %8d %8d  %s\n", c.Rule.Priority, label(c.Rule), c.Matched, c.Effective, finding)
+	}
+}
+
+// label is the rule name, or its priority when it has none
+func label(rule models.CategoryRule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return fmt.Sprintf("#%d", rule.Priority)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/ruleset"
+)
+
+func main() {
+	replace := flag.Bool("replace", false, "import: delete the current rules instead of merging by rule name")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"usage:\n  ruleset export <rules.yaml|rules.json>\n  ruleset [-replace] import <rules.yaml|rules.json>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command, path := flag.Arg(0), flag.Arg(1)
+
+	db.Connect()
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	switch command {
+	case "export":
+		set, err := ruleset.Export(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Exported %d rule(s) to %s\n", len(set.Rules), path)
+
+	case "import":
+		added, updated, err := ruleset.Import(path, *replace)
+		var invalid *ruleset.ValidationError
+		if errors.As(err, &invalid) {
+			fmt.Println(invalid.Error())
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Imported %s: %d rule(s) added, %d updated\n", path, added, updated)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/importer"
+	"strings"
+	"time"
+)
+
+// settleTime is how long a file must stay untouched before it is imported,
+// so statements that are still downloading are left alone.
+const settleTime = 5 * time.Second
+
+// watchedFolder is a directory and the import profile for its files
+type watchedFolder struct {
+	path    string
+	profile string
+}
+
+// folderFlags collects repeated -dir flags given as path or path=profile
+type folderFlags []watchedFolder
+
+func (f *folderFlags) String() string {
+	parts := make([]string, 0, len(*f))
+	for _, folder := range *f {
+		parts = append(parts, folder.path+"="+folder.profile)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *folderFlags) Set(value string) error {
+	folder := watchedFolder{path: value, profile: importer.DefaultProfile.Name}
+	if i := strings.LastIndex(value, "="); i > 0 {
+		folder.path, folder.profile = value[:i], value[i+1:]
+	}
+	*f = append(*f, folder)
+	return nil
+}
+
+func main() {
+	var folders folderFlags
+	flag.Var(&folders, "dir", "directory to watch as path or path=profile (repeatable, default ~/Downloads/bank)")
+	archive := flag.String("archive", "", "directory processed files are moved to (default <dir>/archive)")
+	interval := flag.Duration("interval", time.Minute, "how often the directories are scanned")
+	flag.Parse()
+
+	if len(folders) == 0 {
+		_ = folders.Set("~/Downloads/bank")
+	}
+
+	db.Connect()
+	if err := db.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := importer.LoadProfiles(filepath.Join(db.DataDir, "profiles.json")); err != nil {
+		log.Fatal(err)
+	}
+
+	for i := range folders {
+		folders[i].path = expandHome(folders[i].path)
+		if _, err := importer.GetProfile(folders[i].profile); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Watching %s with profile %s", folders[i].path, folders[i].profile)
+	}
+
+	for {
+		for _, folder := range folders {
+			archiveDir := expandHome(*archive)
+			if archiveDir == "" {
+				archiveDir = filepath.Join(folder.path, "archive")
+			}
+			scan(folder, archiveDir)
+		}
+		time.Sleep(*interval)
+	}
+}
+
+// scan imports every settled statement file in the folder and archives it
+func scan(folder watchedFolder, archiveDir string) {
+	entries, err := os.ReadDir(folder.path)
+	if err != nil {
+		log.Printf("Failed to read %s: %v", folder.path, err)
+		return
+	}
+
+	profile, _ := importer.GetProfile(folder.profile)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < settleTime {
+			continue
+		}
+
+		path := filepath.Join(folder.path, entry.Name())
+		if _, err := importer.Detect(path, profile); err != nil {
+			// not a statement file
+			continue
+		}
+		summary, err := importer.ImportFile(context.Background(), path, profile, nil)
+		if err != nil {
+			log.Printf("Failed to import %s: %v", path, err)
+			moveFile(path, filepath.Join(archiveDir, "failed"))
+			continue
+		}
+
+		log.Printf("Imported %s: %d imported, %d skipped, %d failed",
+			path, summary.Imported, summary.Skipped, summary.Failed)
+		for _, e := range summary.Errors {
+			log.Printf("  %s", e)
+		}
+
+		moveFile(path, archiveDir)
+
+		if err := db.PublishImportCompleted(entry.Name(), summary); err != nil {
+			log.Printf("Failed to publish import notification: %v", err)
+		}
+	}
+}
+
+// moveFile moves a file into dir, prefixing it with a timestamp so files
+// with the same name from different weeks do not overwrite each other.
+func moveFile(path, dir string) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		log.Printf("Failed to create %s: %v", dir, err)
+		return
+	}
+
+	target := filepath.Join(dir, fmt.Sprintf("%s_%s",
+		time.Now().Format("20060102-150405"),
+		filepath.Base(path),
+	))
+	if err := os.Rename(path, target); err != nil {
+		log.Printf("Failed to move %s to %s: %v", path, dir, err)
+	}
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"peronal_finance_cli_manager/internal/db"
 
 	"github.com/streadway/amqp"
 	"gopkg.in/gomail.v2"
@@ -31,7 +32,7 @@ func main() {
 		}
 	}(ch)
 
-	q, err := ch.QueueDeclare("budget_alerts", true, false, false, false, nil)
+	q, err := ch.QueueDeclare(db.AlertQueue, true, false, false, false, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -47,13 +48,28 @@ func main() {
 	go func() {
 		for msg := range msgs {
 			fmt.Println("Received alert:", string(msg.Body))
-			sendEmail("user@example.com", "Budget Alert", string(msg.Body))
+			sendEmail("user@example.com", alertSubject(msg.Type), string(msg.Body))
 		}
 	}()
 
 	<-forever
 }
 
+func alertSubject(kind string) string {
+	switch kind {
+	case db.AlertImportCompleted:
+		return "Import Completed"
+	case db.AlertGoalMilestone:
+		return "Savings Goal"
+	case db.AlertBudgetWarning:
+		return "Budget Warning"
+	case db.AlertBudgetProjected:
+		return "Budget Forecast"
+	default:
+		return "Budget Alert"
+	}
+}
+
 func sendEmail(to, subject, body string) {
 	m := gomail.NewMessage()
 	m.SetHeader("From", "no-reply@example.com")
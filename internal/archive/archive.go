@@ -0,0 +1,856 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/merchant"
+	"peronal_finance_cli_manager/internal/models"
+	"peronal_finance_cli_manager/internal/rules"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the archive format written by Dump. Restore reads archives up
+// to this version. Version 2 added the categorization rules, version 3 rule
+// conditions and actions and the transaction fields they set, version 4
+// the merchants, version 5 budget periods, version 6 budget history,
+// version 7 envelope rollover and money moves, version 8 category kinds
+// and zero-based budgeting, version 9 alert thresholds, version 10 savings
+// goals, version 11 shares of income and budget templates.
+const Version = 11
+
+// Archive is the JSON document holding the whole ledger. Records refer to
+// each other by the ids inside the archive, categories by name.
+type Archive struct {
+	Version             int                  `json:"version"`
+	CreatedAt           time.Time            `json:"created_at"`
+	BudgetMode          string               `json:"budget_mode,omitempty"`
+	BudgetTemplate      string               `json:"budget_template,omitempty"`
+	ExpectedIncome      float32              `json:"expected_income,omitempty"`
+	Categories          []Category           `json:"categories"`
+	ImportBatches       []ImportBatch        `json:"import_batches"`
+	Transactions        []Transaction        `json:"transactions"`
+	DuplicateDismissals []DuplicateDismissal `json:"duplicate_dismissals"`
+	Rules               []Rule               `json:"rules"`
+	Merchants           []Merchant           `json:"merchants,omitempty"`
+	EnvelopeMoves       []EnvelopeMove       `json:"envelope_moves,omitempty"`
+	Goals               []Goal               `json:"goals,omitempty"`
+}
+
+// Category is a category with its budget; ImportBatch is set when an
+// import created it. The budget covers Period, monthly when empty, starting
+// on Anchor (YYYY-MM-DD), or following the calendar when empty. History
+// holds the budget amounts set over time. Rollover, none when empty, is
+// what happens to money left at the end of a period, rolling over since
+// the period holding RolloverFrom. Kind is expense when empty; Assigned
+// holds the money assigned to it in zero-based budgeting. Alerts are the
+// alert thresholds in percent of the budget, the defaults when empty.
+// Percent, when set, makes the budget that share of income; Group is the
+// budget template group.
+type Category struct {
+	Name         string         `json:"name"`
+	Kind         string         `json:"kind,omitempty"`
+	Budget       float32        `json:"budget"`
+	Period       string         `json:"period,omitempty"`
+	Anchor       string         `json:"anchor,omitempty"`
+	History      []BudgetAmount `json:"history,omitempty"`
+	Rollover     string         `json:"rollover,omitempty"`
+	RolloverFrom string         `json:"rollover_from,omitempty"`
+	Assigned     []Assignment   `json:"assigned,omitempty"`
+	Alerts       []int          `json:"alerts,omitempty"`
+	Percent      float32        `json:"percent,omitempty"`
+	Group        string         `json:"group,omitempty"`
+	ImportBatch  *uint          `json:"import_batch,omitempty"`
+}
+
+// BudgetAmount is a budget amount from the period starting on Start, empty
+// for the first amount, up to the period starting on End, when set
+type BudgetAmount struct {
+	Start  string  `json:"start,omitempty"`
+	End    string  `json:"end,omitempty"`
+	Amount float32 `json:"amount"`
+}
+
+// Assignment is money assigned to a category for its budget period
+// holding Date
+type Assignment struct {
+	Date   string  `json:"date"`
+	Amount float32 `json:"amount"`
+}
+
+// EnvelopeMove is budget money moved between two categories
+type EnvelopeMove struct {
+	Date   string  `json:"date"`
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Amount float32 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+// Goal is a savings goal, linked to an account or a category by name.
+// Milestone is the highest milestone an alert was sent for.
+type Goal struct {
+	Name          string         `json:"name"`
+	Target        float32        `json:"target"`
+	Start         string         `json:"start"`
+	TargetDate    string         `json:"target_date"`
+	Account       string         `json:"account,omitempty"`
+	Category      string         `json:"category,omitempty"`
+	Milestone     int            `json:"milestone,omitempty"`
+	Contributions []Contribution `json:"contributions,omitempty"`
+}
+
+// Contribution is money put aside for a goal
+type Contribution struct {
+	Date   string  `json:"date"`
+	Amount float32 `json:"amount"`
+	Note   string  `json:"note,omitempty"`
+}
+
+// ImportBatch is one import of a statement file
+type ImportBatch struct {
+	ID         uint       `json:"id"`
+	FileName   string     `json:"file_name"`
+	FileHash   string     `json:"file_hash"`
+	Profile    string     `json:"profile"`
+	Imported   int        `json:"imported"`
+	Skipped    int        `json:"skipped"`
+	Failed     int        `json:"failed"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevertedAt *time.Time `json:"reverted_at,omitempty"`
+}
+
+// Transaction refers to its category by name
+type Transaction struct {
+	ID          uint    `json:"id"`
+	Date        string  `json:"date"`
+	Amount      float32 `json:"amount"`
+	Category    string  `json:"category"`
+	Payee       string  `json:"payee,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Account     string  `json:"account,omitempty"`
+	Currency    string  `json:"currency,omitempty"`
+	Tags        string  `json:"tags,omitempty"`
+	Transfer    bool    `json:"transfer,omitempty"`
+	ImportID    string  `json:"import_id,omitempty"`
+	ImportBatch *uint   `json:"import_batch,omitempty"`
+	SplitGroup  string  `json:"split_group,omitempty"`
+}
+
+// DuplicateDismissal is a pair of transactions reviewed as not duplicate
+type DuplicateDismissal struct {
+	First  uint `json:"first"`
+	Second uint `json:"second"`
+}
+
+// Rule is a categorization rule; rules are listed in priority order
+type Rule struct {
+	Name       string                 `json:"name,omitempty"`
+	Pattern    string                 `json:"pattern,omitempty"`
+	Category   string                 `json:"category,omitempty"`
+	Priority   int                    `json:"priority"`
+	Enabled    bool                   `json:"enabled"`
+	Match      string                 `json:"match,omitempty"`
+	Conditions []models.RuleCondition `json:"conditions,omitempty"`
+	Actions    []models.RuleAction    `json:"actions,omitempty"`
+	Stop       bool                   `json:"stop,omitempty"`
+}
+
+// Merchant is a clean payee name with the alias patterns of its raw
+// descriptions and an optional default category
+type Merchant struct {
+	Name     string   `json:"name"`
+	Category string   `json:"category,omitempty"`
+	Aliases  []string `json:"aliases"`
+}
+
+// ValidationError lists every problem that stopped a restore
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("archive not restored, %d problem(s):\n  %s",
+		len(e.Problems), strings.Join(e.Problems, "\n  "))
+}
+
+// Dump writes the whole ledger to a JSON archive file
+func Dump(path string) (*Archive, error) {
+	snapshot, err := db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	a := FromSnapshot(snapshot)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Write(file); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return a, file.Close()
+}
+
+// Restore loads a JSON archive file into the ledger. The archive is checked
+// first, on its own and against what the ledger already holds; any problem
+// is reported in a *ValidationError and nothing is loaded.
+func Restore(path string) (*Archive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(file *os.File) {
+		err := file.Close()
+		if err != nil {
+
+		}
+	}(file)
+
+	a, err := Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, problems := a.ToSnapshot()
+	conflicts, err := conflicts(a)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, conflicts...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return a, db.RestoreSnapshot(snapshot)
+}
+
+// FromSnapshot turns the records of the ledger into an archive
+func FromSnapshot(s models.Snapshot) *Archive {
+	a := &Archive{
+		Version:             Version,
+		CreatedAt:           time.Now().UTC(),
+		Categories:          make([]Category, 0, len(s.Categories)),
+		ImportBatches:       make([]ImportBatch, 0, len(s.ImportBatches)),
+		Transactions:        make([]Transaction, 0, len(s.Transactions)),
+		DuplicateDismissals: make([]DuplicateDismissal, 0, len(s.DuplicateDismissals)),
+		Rules:               make([]Rule, 0, len(s.CategoryRules)),
+		Merchants:           make([]Merchant, 0, len(s.Merchants)),
+		EnvelopeMoves:       make([]EnvelopeMove, 0, len(s.EnvelopeTransfers)),
+		Goals:               make([]Goal, 0, len(s.Goals)),
+	}
+	for _, setting := range s.Settings {
+		switch setting.Key {
+		case db.SettingBudgetMode:
+			if setting.Value != budget.ModeClassic {
+				a.BudgetMode = setting.Value
+			}
+		case db.SettingBudgetTemplate:
+			a.BudgetTemplate = setting.Value
+		case db.SettingExpectedIncome:
+			if amount, err := strconv.ParseFloat(setting.Value, 32); err == nil {
+				a.ExpectedIncome = float32(amount)
+			}
+		}
+	}
+
+	names := make(map[uint]string, len(s.Categories))
+	for _, c := range s.Categories {
+		names[c.ID] = c.Name
+		category := Category{
+			Name:        c.Name,
+			Budget:      c.Budget,
+			Period:      c.BudgetPeriod,
+			ImportBatch: c.BatchID,
+		}
+		if !c.BudgetAnchor.IsZero() {
+			category.Anchor = c.BudgetAnchor.Format("2006-01-02")
+		}
+		if c.Rollover != budget.RolloverNone {
+			category.Rollover = c.Rollover
+		}
+		if c.Kind != models.KindExpense {
+			category.Kind = c.Kind
+		}
+		category.Alerts = c.AlertThresholds
+		category.Percent = c.BudgetPercent
+		category.Group = c.BudgetGroup
+		if !c.RolloverFrom.IsZero() {
+			category.RolloverFrom = c.RolloverFrom.Format("2006-01-02")
+		}
+		for _, b := range s.BudgetAmounts {
+			if b.CategoryID != c.ID {
+				continue
+			}
+			amount := BudgetAmount{Amount: b.Amount}
+			if !b.Start.IsZero() {
+				amount.Start = b.Start.Format("2006-01-02")
+			}
+			if b.End != nil {
+				amount.End = b.End.Format("2006-01-02")
+			}
+			category.History = append(category.History, amount)
+		}
+		for _, b := range s.BudgetAssignments {
+			if b.CategoryID == c.ID {
+				category.Assigned = append(category.Assigned, Assignment{
+					Date:   b.Date.Format("2006-01-02"),
+					Amount: b.Amount,
+				})
+			}
+		}
+		a.Categories = append(a.Categories, category)
+	}
+	for _, b := range s.ImportBatches {
+		a.ImportBatches = append(a.ImportBatches, ImportBatch{
+			ID:         b.ID,
+			FileName:   b.FileName,
+			FileHash:   b.FileHash,
+			Profile:    b.Profile,
+			Imported:   b.Imported,
+			Skipped:    b.Skipped,
+			Failed:     b.Failed,
+			CreatedAt:  b.CreatedAt,
+			RevertedAt: b.RevertedAt,
+		})
+	}
+	for _, tx := range s.Transactions {
+		a.Transactions = append(a.Transactions, Transaction{
+			ID:          tx.ID,
+			Date:        tx.Date.Format("2006-01-02"),
+			Amount:      tx.Amount,
+			Category:    names[tx.CategoryID],
+			Payee:       tx.Payee,
+			Description: tx.Description,
+			Account:     tx.Account,
+			Currency:    tx.Currency,
+			Tags:        tx.Tags,
+			Transfer:    tx.IsTransfer,
+			ImportID:    tx.ImportID,
+			ImportBatch: tx.BatchID,
+			SplitGroup:  tx.SplitGroup,
+		})
+	}
+	for _, d := range s.DuplicateDismissals {
+		a.DuplicateDismissals = append(a.DuplicateDismissals, DuplicateDismissal{
+			First:  d.FirstID,
+			Second: d.SecondID,
+		})
+	}
+	for _, r := range s.CategoryRules {
+		rule := Rule{
+			Name:       r.Name,
+			Pattern:    r.Pattern,
+			Priority:   r.Priority,
+			Enabled:    r.Enabled,
+			Match:      r.Match,
+			Conditions: r.Conditions,
+			Actions:    r.Actions,
+			Stop:       r.Stop,
+		}
+		if r.CategoryID != nil {
+			rule.Category = names[*r.CategoryID]
+		}
+		a.Rules = append(a.Rules, rule)
+	}
+	for _, m := range s.Merchants {
+		merchant := Merchant{Name: m.Name, Aliases: make([]string, 0, len(m.Aliases))}
+		if m.CategoryID != nil {
+			merchant.Category = names[*m.CategoryID]
+		}
+		for _, alias := range m.Aliases {
+			merchant.Aliases = append(merchant.Aliases, alias.Pattern)
+		}
+		a.Merchants = append(a.Merchants, merchant)
+	}
+	for _, e := range s.EnvelopeTransfers {
+		a.EnvelopeMoves = append(a.EnvelopeMoves, EnvelopeMove{
+			Date:   e.Date.Format("2006-01-02"),
+			From:   names[e.FromCategoryID],
+			To:     names[e.ToCategoryID],
+			Amount: e.Amount,
+			Reason: e.Reason,
+		})
+	}
+	for _, g := range s.Goals {
+		item := Goal{
+			Name:       g.Name,
+			Target:     g.Target,
+			Start:      g.StartDate.Format("2006-01-02"),
+			TargetDate: g.TargetDate.Format("2006-01-02"),
+			Account:    g.Account,
+			Milestone:  g.Milestone,
+		}
+		if g.CategoryID != nil {
+			item.Category = names[*g.CategoryID]
+		}
+		for _, c := range g.Contributions {
+			item.Contributions = append(item.Contributions, Contribution{
+				Date:   c.Date.Format("2006-01-02"),
+				Amount: c.Amount,
+				Note:   c.Note,
+			})
+		}
+		a.Goals = append(a.Goals, item)
+	}
+	return a
+}
+
+// Write writes the archive as indented JSON
+func (a *Archive) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(a)
+}
+
+// Read decodes an archive and checks that its version can be read
+func Read(r io.Reader) (*Archive, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var a Archive
+	if err := dec.Decode(&a); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	switch {
+	case a.Version == 0:
+		return nil, errors.New("invalid archive: version missing")
+	case a.Version > Version:
+		return nil, fmt.Errorf("archive version %d is newer than the supported version %d", a.Version, Version)
+	}
+	return &a, nil
+}
+
+// ToSnapshot turns the archive into ledger records and checks that every
+// reference inside it resolves. Categories get ids by position.
+func (a *Archive) ToSnapshot() (models.Snapshot, []string) {
+	var s models.Snapshot
+	var problems []string
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if a.BudgetMode != "" {
+		if err := budget.CheckMode(a.BudgetMode); err != nil {
+			problem("%v", err)
+		}
+		s.Settings = append(s.Settings, models.Setting{Key: db.SettingBudgetMode, Value: a.BudgetMode})
+	}
+	if a.BudgetTemplate != "" {
+		if _, err := budget.FindTemplate(a.BudgetTemplate); err != nil {
+			problem("%v", err)
+		}
+		s.Settings = append(s.Settings, models.Setting{Key: db.SettingBudgetTemplate, Value: a.BudgetTemplate})
+	}
+	switch {
+	case a.ExpectedIncome < 0:
+		problem("expected income cannot be negative")
+	case a.ExpectedIncome > 0:
+		s.Settings = append(s.Settings, models.Setting{
+			Key:   db.SettingExpectedIncome,
+			Value: strconv.FormatFloat(float64(a.ExpectedIncome), 'f', 2, 32),
+		})
+	}
+
+	batches := make(map[uint]bool, len(a.ImportBatches))
+	for _, b := range a.ImportBatches {
+		if batches[b.ID] {
+			problem("import batch %d: listed twice", b.ID)
+		}
+		batches[b.ID] = true
+		s.ImportBatches = append(s.ImportBatches, models.ImportBatch{
+			ID:         b.ID,
+			FileName:   b.FileName,
+			FileHash:   b.FileHash,
+			Profile:    b.Profile,
+			Imported:   b.Imported,
+			Skipped:    b.Skipped,
+			Failed:     b.Failed,
+			CreatedAt:  b.CreatedAt,
+			RevertedAt: b.RevertedAt,
+		})
+	}
+	checkBatch := func(owner string, id *uint) {
+		if id != nil && !batches[*id] {
+			problem("%s: unknown import batch %d", owner, *id)
+		}
+	}
+
+	categories := make(map[string]uint, len(a.Categories))
+	for i, c := range a.Categories {
+		owner := fmt.Sprintf("category '%s'", c.Name)
+		switch {
+		case strings.TrimSpace(c.Name) == "":
+			problem("category #%d: name is empty", i+1)
+		case categories[c.Name] != 0:
+			problem("%s: listed twice", owner)
+		}
+		checkBatch(owner, c.ImportBatch)
+		if err := budget.Check(c.Period); err != nil {
+			problem("%s: %v", owner, err)
+		}
+		anchor, err := parseOptionalDate(c.Anchor)
+		if err != nil {
+			problem("%s: invalid anchor '%s'", owner, c.Anchor)
+		}
+		period := c.Period
+		if period == "" {
+			period = budget.Monthly
+		}
+		if err := budget.CheckRollover(c.Rollover); err != nil {
+			problem("%s: %v", owner, err)
+		}
+		rollover := c.Rollover
+		if rollover == "" {
+			rollover = budget.RolloverNone
+		}
+		rolloverFrom, err := parseOptionalDate(c.RolloverFrom)
+		if err != nil {
+			problem("%s: invalid rollover_from '%s'", owner, c.RolloverFrom)
+		}
+		for _, t := range c.Alerts {
+			if t <= 0 {
+				problem("%s: invalid alert threshold %d", owner, t)
+			}
+		}
+		if err := budget.CheckPercent(c.Percent); err != nil {
+			problem("%s: %v", owner, err)
+		}
+		kind := c.Kind
+		switch kind {
+		case "":
+			kind = models.KindExpense
+		case models.KindExpense, models.KindIncome:
+		default:
+			problem("%s: unknown kind '%s'", owner, c.Kind)
+		}
+
+		id := uint(i + 1)
+		for j, h := range c.History {
+			b := models.BudgetAmount{ID: uint(len(s.BudgetAmounts) + 1), CategoryID: id, Amount: h.Amount}
+			start, errStart := parseOptionalDate(h.Start)
+			end, errEnd := parseOptionalDate(h.End)
+			if errStart != nil || errEnd != nil {
+				problem("%s: budget history #%d: invalid date", owner, j+1)
+			}
+			b.Start = start
+			if !end.IsZero() {
+				b.End = &end
+			}
+			s.BudgetAmounts = append(s.BudgetAmounts, b)
+		}
+		for j, assigned := range c.Assigned {
+			date, err := time.Parse("2006-01-02", assigned.Date)
+			if err != nil {
+				problem("%s: assignment #%d: invalid date '%s'", owner, j+1, assigned.Date)
+			}
+			s.BudgetAssignments = append(s.BudgetAssignments, models.BudgetAssignment{
+				ID:         uint(len(s.BudgetAssignments) + 1),
+				CategoryID: id,
+				Date:       date,
+				Amount:     assigned.Amount,
+			})
+		}
+		categories[c.Name] = id
+		s.Categories = append(s.Categories, models.Category{
+			ID:              id,
+			Name:            c.Name,
+			Kind:            kind,
+			Budget:          c.Budget,
+			BudgetPeriod:    period,
+			BudgetAnchor:    anchor,
+			Rollover:        rollover,
+			RolloverFrom:    rolloverFrom,
+			AlertThresholds: c.Alerts,
+			BudgetPercent:   c.Percent,
+			BudgetGroup:     c.Group,
+			BatchID:         c.ImportBatch,
+		})
+	}
+
+	transactions := make(map[uint]bool, len(a.Transactions))
+	importIDs := make(map[string]uint)
+	for _, tx := range a.Transactions {
+		owner := fmt.Sprintf("transaction %d", tx.ID)
+		if transactions[tx.ID] {
+			problem("%s: listed twice", owner)
+		}
+		transactions[tx.ID] = true
+
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			problem("%s: invalid date '%s'", owner, tx.Date)
+		}
+		categoryID, ok := categories[tx.Category]
+		if !ok {
+			problem("%s: unknown category '%s'", owner, tx.Category)
+		}
+		if tx.ImportID != "" {
+			if other, ok := importIDs[tx.ImportID]; ok {
+				problem("%s: import id %s is also used by transaction %d", owner, tx.ImportID, other)
+			}
+			importIDs[tx.ImportID] = tx.ID
+		}
+		checkBatch(owner, tx.ImportBatch)
+
+		s.Transactions = append(s.Transactions, models.Transaction{
+			ID:          tx.ID,
+			CategoryID:  categoryID,
+			Amount:      tx.Amount,
+			Date:        date,
+			Payee:       tx.Payee,
+			Description: tx.Description,
+			Account:     tx.Account,
+			Currency:    tx.Currency,
+			Tags:        tx.Tags,
+			IsTransfer:  tx.Transfer,
+			ImportID:    tx.ImportID,
+			BatchID:     tx.ImportBatch,
+			SplitGroup:  tx.SplitGroup,
+		})
+	}
+
+	for i, d := range a.DuplicateDismissals {
+		if !transactions[d.First] || !transactions[d.Second] {
+			problem("duplicate dismissal #%d: unknown transaction %d or %d", i+1, d.First, d.Second)
+		}
+		s.DuplicateDismissals = append(s.DuplicateDismissals, models.DuplicateDismissal{
+			ID:       uint(i + 1),
+			FirstID:  d.First,
+			SecondID: d.Second,
+		})
+	}
+
+	for i, r := range a.Rules {
+		owner := fmt.Sprintf("rule #%d", i+1)
+		rule := models.CategoryRule{
+			ID:         uint(i + 1),
+			Name:       r.Name,
+			Pattern:    r.Pattern,
+			Priority:   r.Priority,
+			Enabled:    r.Enabled,
+			Match:      r.Match,
+			Conditions: r.Conditions,
+			Actions:    r.Actions,
+			Stop:       r.Stop,
+		}
+		if rule.Match == "" {
+			rule.Match = models.MatchAll
+		}
+		if r.Category != "" {
+			categoryID, ok := categories[r.Category]
+			if !ok {
+				problem("%s: unknown category '%s'", owner, r.Category)
+			}
+			rule.CategoryID = &categoryID
+		}
+		if err := rules.Check(rule); err != nil {
+			problem("%s: %v", owner, err)
+		}
+		for _, action := range rule.Actions {
+			names := []string{action.Value}
+			if action.Type == models.ActionSplit {
+				names = names[:0]
+				for _, part := range action.Splits {
+					names = append(names, part.Category)
+				}
+			} else if action.Type != models.ActionCategory {
+				continue
+			}
+			for _, name := range names {
+				if _, ok := categories[name]; !ok {
+					problem("%s: unknown category '%s'", owner, name)
+				}
+			}
+		}
+		s.CategoryRules = append(s.CategoryRules, rule)
+	}
+
+	merchants := make(map[string]bool, len(a.Merchants))
+	for i, m := range a.Merchants {
+		owner := fmt.Sprintf("merchant '%s'", m.Name)
+		switch {
+		case strings.TrimSpace(m.Name) == "":
+			problem("merchant #%d: name is empty", i+1)
+		case merchants[m.Name]:
+			problem("%s: listed twice", owner)
+		}
+		merchants[m.Name] = true
+
+		record := models.Merchant{ID: uint(i + 1), Name: m.Name}
+		if m.Category != "" {
+			categoryID, ok := categories[m.Category]
+			if !ok {
+				problem("%s: unknown category '%s'", owner, m.Category)
+			}
+			record.CategoryID = &categoryID
+		}
+		for _, pattern := range m.Aliases {
+			if _, err := merchant.Compile(pattern); err != nil {
+				problem("%s: %v", owner, err)
+			}
+			record.Aliases = append(record.Aliases, models.MerchantAlias{Pattern: pattern})
+		}
+		s.Merchants = append(s.Merchants, record)
+	}
+
+	for i, e := range a.EnvelopeMoves {
+		owner := fmt.Sprintf("envelope move #%d", i+1)
+		date, err := time.Parse("2006-01-02", e.Date)
+		if err != nil {
+			problem("%s: invalid date '%s'", owner, e.Date)
+		}
+		from, okFrom := categories[e.From]
+		if !okFrom {
+			problem("%s: unknown category '%s'", owner, e.From)
+		}
+		to, okTo := categories[e.To]
+		if !okTo {
+			problem("%s: unknown category '%s'", owner, e.To)
+		}
+		if e.Amount <= 0 {
+			problem("%s: amount must be positive", owner)
+		}
+		s.EnvelopeTransfers = append(s.EnvelopeTransfers, models.EnvelopeTransfer{
+			ID:             uint(i + 1),
+			Date:           date,
+			FromCategoryID: from,
+			ToCategoryID:   to,
+			Amount:         e.Amount,
+			Reason:         e.Reason,
+		})
+	}
+
+	goals := make(map[string]bool, len(a.Goals))
+	for i, g := range a.Goals {
+		owner := fmt.Sprintf("goal '%s'", g.Name)
+		switch {
+		case strings.TrimSpace(g.Name) == "":
+			problem("goal #%d: name is empty", i+1)
+		case goals[g.Name]:
+			problem("%s: listed twice", owner)
+		}
+		goals[g.Name] = true
+
+		start, errStart := time.Parse("2006-01-02", g.Start)
+		end, errEnd := time.Parse("2006-01-02", g.TargetDate)
+		switch {
+		case errStart != nil || errEnd != nil:
+			problem("%s: invalid start or target date", owner)
+		case !end.After(start):
+			problem("%s: the target date must be after the start", owner)
+		}
+		if g.Target <= 0 {
+			problem("%s: target must be positive", owner)
+		}
+		if g.Account != "" && g.Category != "" {
+			problem("%s: linked to both an account and a category", owner)
+		}
+
+		record := models.Goal{
+			ID:         uint(i + 1),
+			Name:       g.Name,
+			Target:     g.Target,
+			StartDate:  start,
+			TargetDate: end,
+			Account:    g.Account,
+			Milestone:  g.Milestone,
+		}
+		if g.Category != "" {
+			categoryID, ok := categories[g.Category]
+			if !ok {
+				problem("%s: unknown category '%s'", owner, g.Category)
+			}
+			record.CategoryID = &categoryID
+		}
+		for j, c := range g.Contributions {
+			date, err := time.Parse("2006-01-02", c.Date)
+			if err != nil {
+				problem("%s: contribution #%d: invalid date '%s'", owner, j+1, c.Date)
+			}
+			record.Contributions = append(record.Contributions, models.GoalContribution{
+				Date:   date,
+				Amount: c.Amount,
+				Note:   c.Note,
+			})
+		}
+		s.Goals = append(s.Goals, record)
+	}
+
+	return s, problems
+}
+
+// parseOptionalDate reads a YYYY-MM-DD date; empty is the zero time
+func parseOptionalDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// conflicts compares the archive with what the ledger already holds: a
+// category of the same name must have the same budget, and no transaction
+// may already have been imported. A transaction entered by hand has no
+// import identity; it conflicts with one of the same date, amount, payee
+// and category, each one in the ledger matching once.
+func conflicts(a *Archive) ([]string, error) {
+	var problems []string
+
+	existing, err := db.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+	budgets := make(map[string]float32, len(existing))
+	for _, c := range existing {
+		budgets[c.Name] = c.Budget
+	}
+	for _, c := range a.Categories {
+		if budget, ok := budgets[c.Name]; ok && budget != c.Budget {
+			problems = append(problems, fmt.Sprintf(
+				"category '%s': budget %.2f conflicts with existing budget %.2f", c.Name, c.Budget, budget))
+		}
+	}
+
+	var ids []string
+	for _, tx := range a.Transactions {
+		if tx.ImportID != "" {
+			ids = append(ids, tx.ImportID)
+		}
+	}
+	imported, err := db.ImportedIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range a.Transactions {
+		if imported[tx.ImportID] {
+			problems = append(problems, fmt.Sprintf(
+				"transaction %d: import id %s already exists", tx.ID, tx.ImportID))
+		}
+	}
+
+	manual, err := db.ManualFingerprints()
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range a.Transactions {
+		if tx.ImportID != "" {
+			continue
+		}
+		fingerprint := db.ManualFingerprint(tx.Date, tx.Amount, tx.Payee, tx.Category)
+		if manual[fingerprint] > 0 {
+			manual[fingerprint]--
+			problems = append(problems, fmt.Sprintf(
+				"transaction %d: %s %.2f %s (%s) already exists", tx.ID, tx.Date, tx.Amount, tx.Payee, tx.Category))
+		}
+	}
+
+	return problems, nil
+}
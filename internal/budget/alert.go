@@ -0,0 +1,72 @@
+package budget
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultThresholds are the alert thresholds, in percent of the budget, of
+// a category without its own
+var DefaultThresholds = []int{80, 100}
+
+// ParseThresholds reads a list of percentages such as "50, 80, 100, 120";
+// empty means the defaults. The list comes back sorted without repeats.
+func ParseThresholds(value string) ([]int, error) {
+	var thresholds []int
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '%' }) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid alert threshold '%s', use percentages such as 80, 100", field)
+		}
+		thresholds = append(thresholds, n)
+	}
+	slices.Sort(thresholds)
+	return slices.Compact(thresholds), nil
+}
+
+// FormatThresholds writes thresholds the way ParseThresholds reads them
+func FormatThresholds(thresholds []int) string {
+	parts := make([]string, 0, len(thresholds))
+	for _, t := range thresholds {
+		parts = append(parts, strconv.Itoa(t))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Thresholds returns the thresholds in effect: the given ones, or the
+// defaults when there are none
+func Thresholds(thresholds []int) []int {
+	if len(thresholds) == 0 {
+		return DefaultThresholds
+	}
+	return thresholds
+}
+
+// Percent is how much of the envelope is spent, in percent. Spending from
+// an empty envelope is infinitely over it.
+func (e Envelope) Percent() float64 {
+	available := float64(e.Available())
+	spent := float64(e.Spent)
+	switch {
+	case spent <= 0:
+		return 0
+	case available <= 0:
+		return math.Inf(1)
+	}
+	return spent / available * 100
+}
+
+// Crossed returns the thresholds the spending of the envelope has reached
+func (e Envelope) Crossed(thresholds []int) []int {
+	percent := e.Percent()
+	var crossed []int
+	for _, t := range thresholds {
+		if percent >= float64(t) {
+			crossed = append(crossed, t)
+		}
+	}
+	return crossed
+}
@@ -0,0 +1,24 @@
+package budget
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"time"
+)
+
+// AmountFor is the budget of a period: the amount of the history entry
+// with the latest start that covers the period, the later entry when two
+// start together, or base when none does
+func AmountFor(history []models.BudgetAmount, base float32, p Period) float32 {
+	amount := base
+	var latest *time.Time
+	for _, h := range history {
+		if h.Start.After(p.Start) || (h.End != nil && !h.End.After(p.Start)) {
+			continue
+		}
+		if latest == nil || !h.Start.Before(*latest) {
+			latest = &h.Start
+			amount = h.Amount
+		}
+	}
+	return amount
+}
@@ -0,0 +1,43 @@
+package budget
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestAmountFor(t *testing.T) {
+	end := func(t time.Time) *time.Time { return &t }
+	history := []models.BudgetAmount{
+		{Start: date(2026, time.January, 1), Amount: 300},
+		{Start: date(2026, time.March, 1), Amount: 400},
+		// a change for one period only
+		{Start: date(2026, time.May, 1), End: end(date(2026, time.June, 1)), Amount: 600},
+		// set twice on the same day, the later one wins
+		{Start: date(2026, time.August, 1), Amount: 450},
+		{Start: date(2026, time.August, 1), Amount: 500},
+	}
+	month := func(m time.Month) Period { return Containing(Monthly, time.Time{}, date(2026, m, 1)) }
+
+	tests := []struct {
+		name    string
+		history []models.BudgetAmount
+		period  Period
+		want    float32
+	}{
+		{"no history is the base amount", nil, month(time.March), 250},
+		{"before the history is the base amount", history, Containing(Monthly, time.Time{}, date(2025, time.December, 1)), 250},
+		{"first entry", history, month(time.February), 300},
+		{"latest entry covering the period", history, month(time.April), 400},
+		{"entry for one period", history, month(time.May), 600},
+		{"after an entry ends", history, month(time.June), 400},
+		{"later of two on the same day", history, month(time.September), 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AmountFor(tt.history, 250, tt.period); got != tt.want {
+				t.Errorf("AmountFor = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
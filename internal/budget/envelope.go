@@ -0,0 +1,60 @@
+package budget
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rollover modes: what happens to the money left in an envelope at the end
+// of a period
+const (
+	RolloverNone    = "none"    // every period starts from its budget
+	RolloverSurplus = "surplus" // unspent money carries over, overspending does not
+	RolloverBoth    = "both"    // unspent money carries over and overspending is taken from the next period
+)
+
+// RolloverModes lists the rollover modes
+var RolloverModes = []string{RolloverNone, RolloverSurplus, RolloverBoth}
+
+// CheckRollover reports an unknown rollover mode; empty means none
+func CheckRollover(mode string) error {
+	switch mode {
+	case "", RolloverNone, RolloverSurplus, RolloverBoth:
+		return nil
+	}
+	return fmt.Errorf("unknown rollover '%s', use %s", mode, strings.Join(RolloverModes, ", "))
+}
+
+// Envelope is the money of a category for one budget period
+type Envelope struct {
+	Period Period
+	// Budget is the amount set for the period
+	Budget float32
+	// Carried rolled over from the period before
+	Carried float32
+	// Moved is the money moved in from other envelopes, negative when more
+	// was moved out
+	Moved float32
+	Spent float32
+}
+
+// Available is what the envelope holds for the period
+func (e Envelope) Available() float32 {
+	return e.Budget + e.Carried + e.Moved
+}
+
+// Left is what remains after the spending, negative when overspent
+func (e Envelope) Left() float32 {
+	return e.Available() - e.Spent
+}
+
+// Carry is what rolls over into the next period
+func (e Envelope) Carry(mode string) float32 {
+	switch mode {
+	case RolloverSurplus:
+		return max(e.Left(), 0)
+	case RolloverBoth:
+		return e.Left()
+	}
+	return 0
+}
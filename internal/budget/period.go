@@ -0,0 +1,141 @@
+package budget
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Budget periods
+const (
+	Weekly    = "weekly"
+	Monthly   = "monthly"
+	Quarterly = "quarterly"
+	Yearly    = "yearly"
+)
+
+// calendarAnchor is both a Monday and the first day of a year, so periods
+// anchored on it follow the calendar
+var calendarAnchor = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Periods lists the budget periods from the shortest
+var Periods = []string{Weekly, Monthly, Quarterly, Yearly}
+
+// Check reports an unknown budget period; empty means monthly
+func Check(kind string) error {
+	switch kind {
+	case "", Weekly, Monthly, Quarterly, Yearly:
+		return nil
+	}
+	return fmt.Errorf("unknown budget period '%s', use %s", kind, strings.Join(Periods, ", "))
+}
+
+// Period is one occurrence of a budget period. Start is its first day and
+// End the first day of the next period, both at midnight UTC.
+type Period struct {
+	Kind  string
+	Start time.Time
+	End   time.Time
+
+	anchor time.Time
+	index  int
+}
+
+// Containing returns the period holding date, for a budget of the given
+// kind that starts over on anchor and every period before and after it. A
+// zero anchor means the calendar: weeks from Monday, months from the 1st,
+// quarters from January, April, July and October, years from January 1st.
+// A monthly anchor on the 31st starts shorter months on their last day.
+func Containing(kind string, anchor, date time.Time) Period {
+	if kind == "" {
+		kind = Monthly
+	}
+	if anchor.IsZero() {
+		anchor = calendarAnchor
+	}
+	p := Period{Kind: kind, anchor: day(anchor)}
+	date = day(date)
+
+	// estimate the index, then correct it
+	if kind == Weekly {
+		p.index = floorDiv(int(date.Sub(p.anchor).Hours()/24), 7)
+	} else {
+		months := (date.Year()-p.anchor.Year())*12 + int(date.Month()-p.anchor.Month())
+		p.index = floorDiv(months, p.months())
+	}
+	for p.start(p.index).After(date) {
+		p.index--
+	}
+	for !p.start(p.index + 1).After(date) {
+		p.index++
+	}
+	return p.at(p.index)
+}
+
+// Shift returns the period n periods later, or earlier when n < 0
+func (p Period) Shift(n int) Period {
+	return p.at(p.index + n)
+}
+
+// Contains reports whether date falls within the period
+func (p Period) Contains(date time.Time) bool {
+	date = day(date)
+	return !date.Before(p.Start) && date.Before(p.End)
+}
+
+// Label names the period: "2026-10" for a calendar month, "2026 Q4",
+// "2026", or the first and last day otherwise
+func (p Period) Label() string {
+	last := p.End.AddDate(0, 0, -1)
+	if p.Start.Day() == 1 {
+		switch {
+		case p.Kind == Monthly:
+			return p.Start.Format("2006-01")
+		case p.Kind == Quarterly && (p.Start.Month()-1)%3 == 0:
+			return fmt.Sprintf("%d Q%d", p.Start.Year(), (p.Start.Month()-1)/3+1)
+		case p.Kind == Yearly && p.Start.Month() == time.January:
+			return p.Start.Format("2006")
+		}
+	}
+	return p.Start.Format("2006-01-02") + " – " + last.Format("2006-01-02")
+}
+
+func (p Period) at(index int) Period {
+	p.index = index
+	p.Start = p.start(index)
+	p.End = p.start(index + 1)
+	return p
+}
+
+// start is the first day of the period index periods after the anchor
+func (p Period) start(index int) time.Time {
+	if p.Kind == Weekly {
+		return p.anchor.AddDate(0, 0, 7*index)
+	}
+	first := time.Date(p.anchor.Year(), p.anchor.Month()+time.Month(index*p.months()), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(p.anchor.Day(), last)-1)
+}
+
+func (p Period) months() int {
+	switch p.Kind {
+	case Quarterly:
+		return 3
+	case Yearly:
+		return 12
+	}
+	return 1
+}
+
+// day drops the time of day, keeping the calendar date
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,90 @@
+package budget
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestContaining(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      string
+		anchor    time.Time
+		date      time.Time
+		start     time.Time
+		end       time.Time
+		wantLabel string
+	}{
+		{"calendar month", Monthly, time.Time{}, date(2026, time.March, 31),
+			date(2026, time.March, 1), date(2026, time.April, 1), "2026-03"},
+		{"empty kind is monthly", "", time.Time{}, date(2026, time.March, 1),
+			date(2026, time.March, 1), date(2026, time.April, 1), "2026-03"},
+		{"calendar week from Monday", Weekly, time.Time{}, date(2026, time.March, 4),
+			date(2026, time.March, 2), date(2026, time.March, 9), "2026-03-02 – 2026-03-08"},
+		{"calendar week across a year end", Weekly, time.Time{}, date(2027, time.January, 1),
+			date(2026, time.December, 28), date(2027, time.January, 4), "2026-12-28 – 2027-01-03"},
+		{"calendar quarter", Quarterly, time.Time{}, date(2026, time.May, 15),
+			date(2026, time.April, 1), date(2026, time.July, 1), "2026 Q2"},
+		{"calendar year", Yearly, time.Time{}, date(2026, time.December, 31),
+			date(2026, time.January, 1), date(2027, time.January, 1), "2026"},
+		{"anchor on the 31st in February", Monthly, date(2026, time.January, 31), date(2026, time.February, 15),
+			date(2026, time.January, 31), date(2026, time.February, 28), "2026-01-31 – 2026-02-27"},
+		{"anchor on the 31st from the end of February", Monthly, date(2026, time.January, 31), date(2026, time.February, 28),
+			date(2026, time.February, 28), date(2026, time.March, 31), "2026-02-28 – 2026-03-30"},
+		{"anchor on the 31st in a leap February", Monthly, date(2028, time.January, 31), date(2028, time.February, 29),
+			date(2028, time.February, 29), date(2028, time.March, 31), "2028-02-29 – 2028-03-30"},
+		{"anchor on the 31st before the anchor", Monthly, date(2026, time.January, 31), date(2025, time.December, 15),
+			date(2025, time.November, 30), date(2025, time.December, 31), "2025-11-30 – 2025-12-30"},
+		{"anchor on the 30th in a 31-day month", Monthly, date(2026, time.April, 30), date(2026, time.May, 31),
+			date(2026, time.May, 30), date(2026, time.June, 30), "2026-05-30 – 2026-06-29"},
+		{"yearly anchor on a leap day", Yearly, date(2028, time.February, 29), date(2029, time.February, 27),
+			date(2028, time.February, 29), date(2029, time.February, 28), "2028-02-29 – 2029-02-27"},
+		{"yearly anchor on a leap day a year on", Yearly, date(2028, time.February, 29), date(2029, time.March, 1),
+			date(2029, time.February, 28), date(2030, time.February, 28), "2029-02-28 – 2030-02-27"},
+		{"yearly anchor on a leap day back in a leap year", Yearly, date(2028, time.February, 29), date(2032, time.March, 1),
+			date(2032, time.February, 29), date(2033, time.February, 28), "2032-02-29 – 2033-02-27"},
+		{"quarter anchored on the 31st", Quarterly, date(2026, time.January, 31), date(2026, time.May, 1),
+			date(2026, time.April, 30), date(2026, time.July, 31), "2026-04-30 – 2026-07-30"},
+		{"time of day is dropped", Monthly, date(2026, time.January, 15), time.Date(2026, time.February, 14, 23, 59, 0, 0, time.UTC),
+			date(2026, time.January, 15), date(2026, time.February, 15), "2026-01-15 – 2026-02-14"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Containing(tt.kind, tt.anchor, tt.date)
+			if !p.Start.Equal(tt.start) || !p.End.Equal(tt.end) {
+				t.Fatalf("Containing = %s – %s, want %s – %s", p.Start.Format("2006-01-02"), p.End.Format("2006-01-02"),
+					tt.start.Format("2006-01-02"), tt.end.Format("2006-01-02"))
+			}
+			if !p.Contains(tt.date) {
+				t.Errorf("period does not contain %s", tt.date.Format("2006-01-02"))
+			}
+			if got := p.Label(); got != tt.wantLabel {
+				t.Errorf("Label = %q, want %q", got, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestShift(t *testing.T) {
+	// periods anchored on the 31st stay anchored on it after short months
+	p := Containing(Monthly, date(2026, time.January, 31), date(2026, time.January, 31))
+	want := []time.Time{
+		date(2026, time.January, 31),
+		date(2026, time.February, 28),
+		date(2026, time.March, 31),
+		date(2026, time.April, 30),
+		date(2026, time.May, 31),
+	}
+	for n, start := range want {
+		if got := p.Shift(n).Start; !got.Equal(start) {
+			t.Errorf("Shift(%d) starts %s, want %s", n, got.Format("2006-01-02"), start.Format("2006-01-02"))
+		}
+	}
+	if got := p.Shift(2).Shift(-2); !got.Start.Equal(p.Start) || !got.End.Equal(p.End) {
+		t.Errorf("Shift(2).Shift(-2) = %s, want %s", got.Label(), p.Label())
+	}
+}
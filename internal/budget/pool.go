@@ -0,0 +1,47 @@
+package budget
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Budgeting modes
+const (
+	// ModeClassic gives every category the budget amount set for it
+	ModeClassic = "classic"
+	// ModeZeroBased funds categories only with money assigned from the
+	// income received
+	ModeZeroBased = "zero-based"
+)
+
+// Modes lists the budgeting modes
+var Modes = []string{ModeClassic, ModeZeroBased}
+
+// CheckMode reports an unknown budgeting mode; empty means classic
+func CheckMode(mode string) error {
+	switch mode {
+	case "", ModeClassic, ModeZeroBased:
+		return nil
+	}
+	return fmt.Errorf("unknown budgeting mode '%s', use %s", mode, strings.Join(Modes, ", "))
+}
+
+// Pool is the income waiting to be assigned to categories in zero-based
+// budgeting. Money not assigned in a period stays in the pool, so Received
+// and Assigned add up everything until the end of Period.
+type Pool struct {
+	Period   Period
+	Received float32
+	Assigned float32
+}
+
+// ToBeAssigned is the income not yet given to a category, negative when
+// more was assigned than received
+func (p Pool) ToBeAssigned() float32 {
+	return p.Received - p.Assigned
+}
+
+// Overassigned reports whether more was assigned than received
+func (p Pool) Overassigned() bool {
+	return p.ToBeAssigned() < 0
+}
@@ -0,0 +1,89 @@
+package budget
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Template groups
+const (
+	GroupNeeds   = "needs"
+	GroupWants   = "wants"
+	GroupSavings = "savings"
+)
+
+// Share is the part of income, in percent, a template gives a group of
+// categories. A minimum share, such as savings, is not flagged when
+// exceeded.
+type Share struct {
+	Group   string
+	Percent float64
+	Minimum bool
+}
+
+// Template splits income between groups of categories
+type Template struct {
+	Name   string
+	Shares []Share
+}
+
+// Templates are the ready-made templates
+var Templates = []Template{
+	{Name: "50/30/20", Shares: []Share{
+		{Group: GroupNeeds, Percent: 50},
+		{Group: GroupWants, Percent: 30},
+		{Group: GroupSavings, Percent: 20, Minimum: true},
+	}},
+	{Name: "70/20/10", Shares: []Share{
+		{Group: GroupNeeds, Percent: 70},
+		{Group: GroupWants, Percent: 20},
+		{Group: GroupSavings, Percent: 10, Minimum: true},
+	}},
+}
+
+// FindTemplate returns the template named name; empty means none, the
+// zero Template
+func FindTemplate(name string) (Template, error) {
+	if name == "" {
+		return Template{}, nil
+	}
+	names := make([]string, 0, len(Templates))
+	for _, t := range Templates {
+		if t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return Template{}, fmt.Errorf("unknown budget template '%s', use %s", name, strings.Join(names, ", "))
+}
+
+// NextTemplate is the template after name in Templates, none after the
+// last one
+func NextTemplate(name string) string {
+	for i, t := range Templates {
+		if t.Name == name {
+			if i+1 < len(Templates) {
+				return Templates[i+1].Name
+			}
+			return ""
+		}
+	}
+	return Templates[0].Name
+}
+
+// CheckPercent reports a share of income out of range; 0 means a fixed
+// budget amount
+func CheckPercent(percent float32) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("invalid share of income %.2f%%, use 0 to 100", percent)
+	}
+	return nil
+}
+
+// ExpectedIncome scales a monthly income to the length of the period
+func ExpectedIncome(p Period, monthly float32) float32 {
+	if p.Kind == Weekly {
+		return monthly * 12 / 52
+	}
+	return monthly * float32(p.months())
+}
@@ -0,0 +1,201 @@
+// Package classifier suggests categories for transactions with a naive
+// Bayes model trained on the transactions already categorized.
+package classifier
+
+import (
+	"math"
+	"peronal_finance_cli_manager/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Suggestion is a category with the model's confidence in it, 0..1
+type Suggestion struct {
+	Category   string
+	Confidence float64
+}
+
+// Model is a multinomial naive Bayes model over the words of the payee and
+// description and a bucket of the amount. It learns and forgets one
+// transaction at a time and is safe for concurrent use.
+type Model struct {
+	mu sync.RWMutex
+
+	docs     map[string]int            // transactions per category
+	features map[string]map[string]int // feature counts per category
+	totals   map[string]int            // feature total per category
+	vocab    map[string]int            // categories counting a feature
+	count    int
+}
+
+func New() *Model {
+	return &Model{
+		docs:     make(map[string]int),
+		features: make(map[string]map[string]int),
+		totals:   make(map[string]int),
+		vocab:    make(map[string]int),
+	}
+}
+
+// amountBuckets are the upper bounds of the amount buckets
+var amountBuckets = []float64{10, 50, 100, 500, 1000}
+
+// Features returns the features of a transaction: the lowercase words of
+// its payee and description, without numbers, and its amount bucket
+func Features(tx models.Transaction) []string {
+	var features []string
+	seen := make(map[string]bool)
+	words := strings.FieldsFunc(tx.Payee+" "+tx.Description, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		w = strings.ToLower(w)
+		if len([]rune(w)) < 2 || strings.IndexFunc(w, unicode.IsLetter) < 0 || seen[w] {
+			continue
+		}
+		seen[w] = true
+		features = append(features, w)
+	}
+	if len(features) == 0 {
+		return nil
+	}
+	return append(features, amountFeature(tx.Amount))
+}
+
+func amountFeature(amount float32) string {
+	sign := "out"
+	if amount > 0 {
+		sign = "in"
+	}
+	value := math.Abs(float64(amount))
+	bucket := len(amountBuckets)
+	for i, bound := range amountBuckets {
+		if value < bound {
+			bucket = i
+			break
+		}
+	}
+	return "amount:" + sign + ":" + string(rune('0'+bucket))
+}
+
+// Learn adds a categorized transaction to the model
+func (m *Model) Learn(tx models.Transaction, category string) {
+	m.update(tx, category, 1)
+}
+
+// Forget removes a transaction learned before, e.g. when it moves to
+// another category or is deleted
+func (m *Model) Forget(tx models.Transaction, category string) {
+	m.update(tx, category, -1)
+}
+
+func (m *Model) update(tx models.Transaction, category string, delta int) {
+	features := Features(tx)
+	if category == "" || len(features) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if delta < 0 && m.docs[category] == 0 {
+		return
+	}
+
+	counts := m.features[category]
+	if counts == nil {
+		counts = make(map[string]int)
+		m.features[category] = counts
+	}
+	for _, f := range features {
+		switch {
+		case delta > 0:
+			if counts[f] == 0 {
+				m.vocab[f]++
+			}
+			counts[f]++
+			m.totals[category]++
+		case counts[f] > 0:
+			counts[f]--
+			m.totals[category]--
+			if counts[f] == 0 {
+				delete(counts, f)
+				if m.vocab[f]--; m.vocab[f] == 0 {
+					delete(m.vocab, f)
+				}
+			}
+		}
+	}
+
+	m.docs[category] += delta
+	m.count += delta
+	if m.docs[category] <= 0 {
+		delete(m.docs, category)
+		delete(m.features, category)
+		delete(m.totals, category)
+	}
+}
+
+// Size returns how many transactions the model learned
+func (m *Model) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.count
+}
+
+// Predict returns up to n categories for a transaction, most likely first.
+// It returns nothing when none of the transaction's words were seen.
+func (m *Model) Predict(tx models.Transaction, n int) []Suggestion {
+	features := Features(tx)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	known := 0
+	for _, f := range features {
+		if !strings.HasPrefix(f, "amount:") && m.vocab[f] > 0 {
+			known++
+		}
+	}
+	if known == 0 || m.count == 0 {
+		return nil
+	}
+
+	vocab := float64(len(m.vocab))
+	scores := make([]Suggestion, 0, len(m.docs))
+	best := math.Inf(-1)
+	for category, docs := range m.docs {
+		score := math.Log(float64(docs) / float64(m.count))
+		denominator := float64(m.totals[category]) + vocab
+		for _, f := range features {
+			if m.vocab[f] == 0 {
+				continue
+			}
+			score += math.Log((float64(m.features[category][f]) + 1) / denominator)
+		}
+		scores = append(scores, Suggestion{Category: category, Confidence: score})
+		best = max(best, score)
+	}
+
+	// turn the log scores into probabilities
+	sum := 0.0
+	for i := range scores {
+		scores[i].Confidence = math.Exp(scores[i].Confidence - best)
+		sum += scores[i].Confidence
+	}
+	for i := range scores {
+		scores[i].Confidence /= sum
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Confidence != scores[j].Confidence {
+			return scores[i].Confidence > scores[j].Confidence
+		}
+		return scores[i].Category < scores[j].Category
+	})
+	if len(scores) > n {
+		scores = scores[:n]
+	}
+	return scores
+}
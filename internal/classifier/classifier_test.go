@@ -0,0 +1,102 @@
+package classifier
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"slices"
+	"testing"
+)
+
+func TestFeatures(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   models.Transaction
+		want []string
+	}{
+		{"words and amount bucket", models.Transaction{Payee: "Corner Bakery", Amount: -4.5},
+			[]string{"corner", "bakery", "amount:out:0"}},
+		{"numbers, short and repeated words are dropped", models.Transaction{Payee: "UBER *TRIP 123", Description: "uber trip x 4", Amount: -23},
+			[]string{"uber", "trip", "amount:out:1"}},
+		{"income bucket", models.Transaction{Payee: "Employer", Amount: 2500}, []string{"employer", "amount:in:5"}},
+		{"bucket bound goes up", models.Transaction{Payee: "Store", Amount: -100}, []string{"store", "amount:out:3"}},
+		{"no words, no features", models.Transaction{Payee: "1234 56", Amount: -10}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Features(tt.tx); !slices.Equal(got, tt.want) {
+				t.Errorf("Features = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPredict(t *testing.T) {
+	m := New()
+	learn := func(payee string, amount float32, category string, times int) {
+		for range times {
+			m.Learn(models.Transaction{Payee: payee, Amount: amount}, category)
+		}
+	}
+	learn("Fresh Market", -42, "Groceries", 3)
+	learn("Corner Bakery", -6, "Groceries", 2)
+	learn("Uber Trip", -18, "Transport", 4)
+	learn("City Metro", -3, "Transport", 1)
+	learn("Market Hall Cafe", -9, "Dining", 3)
+
+	tests := []struct {
+		name   string
+		payee  string
+		amount float32
+		// want is the best suggestion, none when empty
+		want string
+	}{
+		{"known payee", "FRESH MARKET 0042", -40, "Groceries"},
+		{"word shared with another category", "Market", -45, "Groceries"},
+		{"word seen in one category only", "Cafe Roma", -9, "Dining"},
+		{"amount bucket breaks a tie of words", "City Trip", -17, "Transport"},
+		{"unseen words", "Gym Membership", -30, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions := m.Predict(models.Transaction{Payee: tt.payee, Amount: tt.amount}, 3)
+			if tt.want == "" {
+				if suggestions != nil {
+					t.Errorf("Predict = %v, want nothing", suggestions)
+				}
+				return
+			}
+			if len(suggestions) != 3 || suggestions[0].Category != tt.want {
+				t.Fatalf("Predict = %v, want %s first of 3", suggestions, tt.want)
+			}
+			total := 0.0
+			for i, s := range suggestions {
+				total += s.Confidence
+				if i > 0 && s.Confidence > suggestions[i-1].Confidence {
+					t.Errorf("suggestions are not sorted by confidence: %v", suggestions)
+				}
+			}
+			if total < 0.999 || total > 1.001 {
+				t.Errorf("confidences add up to %.4f, want 1", total)
+			}
+		})
+	}
+}
+
+func TestForget(t *testing.T) {
+	m := New()
+	tx := models.Transaction{Payee: "Fresh Market", Amount: -42}
+	m.Learn(tx, "Groceries")
+	m.Learn(models.Transaction{Payee: "Uber Trip", Amount: -18}, "Transport")
+
+	m.Forget(tx, "Groceries")
+	if m.Size() != 1 {
+		t.Errorf("Size = %d after forgetting, want 1", m.Size())
+	}
+	if got := m.Predict(tx, 3); got != nil {
+		t.Errorf("Predict = %v for a forgotten payee, want nothing", got)
+	}
+	// forgetting what was never learned changes nothing
+	m.Forget(tx, "Groceries")
+	if m.Size() != 1 {
+		t.Errorf("Size = %d after forgetting twice, want 1", m.Size())
+	}
+}
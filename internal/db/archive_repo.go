@@ -0,0 +1,283 @@
+package db
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GetSnapshot reads every record of the ledger
+func GetSnapshot() (models.Snapshot, error) {
+	var s models.Snapshot
+	err := DB.Transaction(func(db *gorm.DB) error {
+		if err := db.Order("id").Find(&s.Categories).Error; err != nil {
+			return err
+		}
+		if err := db.Order("id").Find(&s.ImportBatches).Error; err != nil {
+			return err
+		}
+		if err := db.Order("id").Find(&s.Transactions).Error; err != nil {
+			return err
+		}
+		if err := db.Order("id").Find(&s.DuplicateDismissals).Error; err != nil {
+			return err
+		}
+		if err := db.Order("priority, id").Find(&s.CategoryRules).Error; err != nil {
+			return err
+		}
+		if err := db.Order("id").Find(&s.BudgetAmounts).Error; err != nil {
+			return err
+		}
+		if err := db.Order("id").Find(&s.EnvelopeTransfers).Error; err != nil {
+			return err
+		}
+		if err := db.Order("id").Find(&s.BudgetAssignments).Error; err != nil {
+			return err
+		}
+		if err := db.Order("key").Find(&s.Settings).Error; err != nil {
+			return err
+		}
+		if err := db.Preload("Contributions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).Order("id").Find(&s.Goals).Error; err != nil {
+			return err
+		}
+		return db.Preload("Aliases", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).Order("id").Find(&s.Merchants).Error
+	})
+	return s, err
+}
+
+// ManualFingerprint identifies a transaction entered by hand, which has no
+// import identity to recognize it by: its date (YYYY-MM-DD), amount, payee
+// and category
+func ManualFingerprint(date string, amount float32, payee, category string) string {
+	return fmt.Sprintf("%s|%.2f|%s|%s", date, amount, payee, category)
+}
+
+// ManualFingerprints counts the transactions without an import identity
+// by their ManualFingerprint
+func ManualFingerprints() (map[string]int, error) {
+	var txs []models.Transaction
+	if err := DB.Preload("Category").
+		Where("import_id = '' OR import_id IS NULL").
+		Find(&txs).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(txs))
+	for _, tx := range txs {
+		counts[ManualFingerprint(tx.Date.Format("2006-01-02"), tx.Amount, tx.Payee, tx.Category.Name)]++
+	}
+	return counts, nil
+}
+
+// RestoreSnapshot writes a snapshot in one database transaction, so either
+// all of it is loaded or nothing. Records get new IDs and references are
+// moved along; a category, merchant or goal whose name already exists is
+// reused.
+func RestoreSnapshot(s models.Snapshot) error {
+	return DB.Transaction(func(db *gorm.DB) error {
+		batches := make(map[uint]uint, len(s.ImportBatches))
+		for _, b := range s.ImportBatches {
+			oldID := b.ID
+			b.ID = 0
+			if err := db.Create(&b).Error; err != nil {
+				return err
+			}
+			batches[oldID] = b.ID
+		}
+		batchRef := func(id *uint) (*uint, error) {
+			if id == nil {
+				return nil, nil
+			}
+			newID, ok := batches[*id]
+			if !ok {
+				return nil, fmt.Errorf("unknown import batch %d", *id)
+			}
+			return &newID, nil
+		}
+
+		categories := make(map[uint]uint, len(s.Categories))
+		created := make(map[uint]bool, len(s.Categories))
+		for _, c := range s.Categories {
+			oldID := c.ID
+
+			var existing models.Category
+			err := db.Where("name = ?", c.Name).Limit(1).Find(&existing).Error
+			if err != nil {
+				return err
+			}
+			if existing.ID != 0 {
+				categories[oldID] = existing.ID
+				continue
+			}
+
+			c.ID = 0
+			if c.BatchID, err = batchRef(c.BatchID); err != nil {
+				return err
+			}
+			if err := db.Create(&c).Error; err != nil {
+				return err
+			}
+			categories[oldID] = c.ID
+			created[c.ID] = true
+		}
+
+		// a category that already existed keeps its own budget history
+		for _, b := range s.BudgetAmounts {
+			categoryID, ok := categories[b.CategoryID]
+			if !ok {
+				return fmt.Errorf("budget amount %d: unknown category %d", b.ID, b.CategoryID)
+			}
+			if !created[categoryID] {
+				continue
+			}
+			b.ID = 0
+			b.CategoryID = categoryID
+			if err := db.Create(&b).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, b := range s.BudgetAssignments {
+			categoryID, ok := categories[b.CategoryID]
+			if !ok {
+				return fmt.Errorf("budget assignment %d: unknown category %d", b.ID, b.CategoryID)
+			}
+			if !created[categoryID] {
+				continue
+			}
+			b.ID = 0
+			b.CategoryID = categoryID
+			if err := db.Create(&b).Error; err != nil {
+				return err
+			}
+		}
+
+		// settings the ledger already has stay as they are
+		for _, setting := range s.Settings {
+			if err := db.Where("key = ?", setting.Key).FirstOrCreate(&setting).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, e := range s.EnvelopeTransfers {
+			from, okFrom := categories[e.FromCategoryID]
+			to, okTo := categories[e.ToCategoryID]
+			if !okFrom || !okTo {
+				return fmt.Errorf("envelope transfer %d: unknown category", e.ID)
+			}
+			e.ID = 0
+			e.FromCategoryID, e.ToCategoryID = from, to
+			if err := db.Omit("FromCategory", "ToCategory").Create(&e).Error; err != nil {
+				return err
+			}
+		}
+
+		transactions := make(map[uint]uint, len(s.Transactions))
+		for _, tx := range s.Transactions {
+			oldID := tx.ID
+			categoryID, ok := categories[tx.CategoryID]
+			if !ok {
+				return fmt.Errorf("transaction %d: unknown category %d", oldID, tx.CategoryID)
+			}
+
+			var err error
+			tx.ID = 0
+			tx.CategoryID = categoryID
+			if tx.BatchID, err = batchRef(tx.BatchID); err != nil {
+				return err
+			}
+			if err := db.Omit("Category").Create(&tx).Error; err != nil {
+				return err
+			}
+			transactions[oldID] = tx.ID
+		}
+
+		for _, d := range s.DuplicateDismissals {
+			first, okFirst := transactions[d.FirstID]
+			second, okSecond := transactions[d.SecondID]
+			if !okFirst || !okSecond {
+				return fmt.Errorf("duplicate dismissal %d: unknown transaction", d.ID)
+			}
+			// pairs are stored with the lower id first
+			if first > second {
+				first, second = second, first
+			}
+			dismissal := models.DuplicateDismissal{FirstID: first, SecondID: second}
+			if err := db.Create(&dismissal).Error; err != nil {
+				return err
+			}
+		}
+
+		defer invalidateRuleCache()
+		defer invalidateClassifier()
+		for _, rule := range s.CategoryRules {
+			if rule.CategoryID != nil {
+				categoryID, ok := categories[*rule.CategoryID]
+				if !ok {
+					return fmt.Errorf("rule %d: unknown category %d", rule.ID, *rule.CategoryID)
+				}
+				rule.CategoryID = &categoryID
+			}
+			rule.ID = 0
+			if err := db.Omit("Category").Create(&rule).Error; err != nil {
+				return err
+			}
+		}
+
+		defer invalidateMerchants()
+		for _, m := range s.Merchants {
+			var count int64
+			if err := db.Model(&models.Merchant{}).Where("name = ?", m.Name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			if m.CategoryID != nil {
+				categoryID, ok := categories[*m.CategoryID]
+				if !ok {
+					return fmt.Errorf("merchant %s: unknown category %d", m.Name, *m.CategoryID)
+				}
+				m.CategoryID = &categoryID
+			}
+			m.ID = 0
+			for i := range m.Aliases {
+				m.Aliases[i].ID = 0
+				m.Aliases[i].MerchantID = 0
+			}
+			if err := db.Omit("Category").Create(&m).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, g := range s.Goals {
+			var count int64
+			if err := db.Model(&models.Goal{}).Where("name = ?", g.Name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			if g.CategoryID != nil {
+				categoryID, ok := categories[*g.CategoryID]
+				if !ok {
+					return fmt.Errorf("goal %s: unknown category %d", g.Name, *g.CategoryID)
+				}
+				g.CategoryID = &categoryID
+			}
+			g.ID = 0
+			for i := range g.Contributions {
+				g.Contributions[i].ID = 0
+				g.Contributions[i].GoalID = 0
+			}
+			if err := db.Omit("Category").Create(&g).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestManualFingerprints(t *testing.T) {
+	openTestDB(t)
+
+	food := models.Category{Name: "Food"}
+	if err := DB.Create(&food).Error; err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	txs := []models.Transaction{
+		{CategoryID: food.ID, Amount: 4.5, Date: day, Payee: "Cafe"},
+		{CategoryID: food.ID, Amount: 4.5, Date: day, Payee: "Cafe"},
+		{CategoryID: food.ID, Amount: 12, Date: day, Payee: "Bakery"},
+		// imported transactions are recognized by their import identity
+		{CategoryID: food.ID, Amount: 30, Date: day, Payee: "Market", ImportID: "sha256:abc"},
+	}
+	if err := DB.Omit("Category").Create(&txs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ManualFingerprints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{
+		ManualFingerprint("2026-03-04", 4.5, "Cafe", "Food"):  2,
+		ManualFingerprint("2026-03-04", 12, "Bakery", "Food"): 1,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ManualFingerprints = %v, want %v", got, want)
+	}
+	for fingerprint, count := range want {
+		if got[fingerprint] != count {
+			t.Errorf("%s counted %d times, want %d", fingerprint, got[fingerprint], count)
+		}
+	}
+}
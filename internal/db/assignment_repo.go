@@ -0,0 +1,105 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// budgetMode returns the budgeting mode of the ledger
+func budgetMode(db *gorm.DB) (string, error) {
+	return getSetting(db, SettingBudgetMode, budget.ModeClassic)
+}
+
+// GetBudgetMode returns the budgeting mode of the ledger, classic unless
+// zero-based budgeting was switched on
+func GetBudgetMode() (string, error) {
+	return budgetMode(DB)
+}
+
+// SetBudgetMode switches between classic and zero-based budgeting
+func SetBudgetMode(mode string) error {
+	if mode == "" {
+		mode = budget.ModeClassic
+	}
+	if err := budget.CheckMode(mode); err != nil {
+		return err
+	}
+	return setSetting(DB, SettingBudgetMode, mode)
+}
+
+// assignedIn sums the money assigned to a category within a budget period
+func assignedIn(db *gorm.DB, categoryID uint, period budget.Period) (float32, error) {
+	var total float32
+	err := db.Model(&models.BudgetAssignment{}).
+		Where("category_id = ?", categoryID).
+		Where("date(date) >= ? AND date(date) < ?",
+			period.Start.Format("2006-01-02"), period.End.Format("2006-01-02")).
+		Select("COALESCE(SUM(amount), 0)").
+		Row().Scan(&total)
+	return total, err
+}
+
+// GetPool returns the to be assigned pool at the end of the calendar month
+// holding date: the income received so far against what was assigned to
+// categories, both from the start of the ledger
+func GetPool(date time.Time) (budget.Pool, error) {
+	pool := budget.Pool{Period: budget.Containing(budget.Monthly, time.Time{}, date)}
+	end := pool.Period.End.Format("2006-01-02")
+
+	err := DB.Model(&models.Transaction{}).
+		Joins("JOIN categories c ON c.id = transactions.category_id").
+		Where("c.kind = ?", models.KindIncome).
+		Where("transactions.is_transfer = ?", false).
+		Where("date(transactions.date) < ?", end).
+		Select("COALESCE(SUM(transactions.amount), 0)").
+		Row().Scan(&pool.Received)
+	if err != nil {
+		return pool, err
+	}
+
+	err = DB.Model(&models.BudgetAssignment{}).
+		Where("date(date) < ?", end).
+		Select("COALESCE(SUM(amount), 0)").
+		Row().Scan(&pool.Assigned)
+	return pool, err
+}
+
+// AssignBudget funds a category from the to be assigned pool for its
+// budget period holding date; a negative amount returns money to the pool.
+// Nothing stops assigning more than was received, the pool then shows as
+// overassigned.
+func AssignBudget(categoryName string, amount float32, date time.Time) (*models.BudgetAssignment, error) {
+	if amount == 0 {
+		return nil, errors.New("the amount to assign cannot be zero")
+	}
+	category, err := GetCategoryByName(categoryName)
+	if err != nil {
+		return nil, fmt.Errorf("category '%s' not found", categoryName)
+	}
+	if category.Kind == models.KindIncome {
+		return nil, fmt.Errorf("'%s' is an income category, assign to expense categories", category.Name)
+	}
+
+	// the start of the period keeps the assignment in it however the
+	// pool months and the category periods line up
+	assignment := models.BudgetAssignment{
+		CategoryID: category.ID,
+		Date:       BudgetPeriod(*category, date).Start,
+		Amount:     amount,
+	}
+	err = DB.Transaction(func(db *gorm.DB) error {
+		if err := db.Create(&assignment).Error; err != nil {
+			return err
+		}
+		return resetBudgetAlerts(db, category.ID, assignment.Date)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
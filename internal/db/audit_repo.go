@@ -0,0 +1,32 @@
+package db
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Audit actions
+const (
+	AuditRecategorize = "recategorize"
+	AuditApplyRules   = "apply_rules"
+	AuditMergePayees  = "merge_payees"
+)
+
+// audit records changes in the audit log; nothing is recorded without
+// changes
+func audit(db *gorm.DB, action, summary string, changes []models.AuditChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return db.Create(&models.AuditEntry{Action: action, Summary: summary, Changes: changes}).Error
+}
+
+// GetAuditEntries returns the audit log, newest first
+func GetAuditEntries() ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	if err := DB.Order("id DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
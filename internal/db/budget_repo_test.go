@@ -0,0 +1,52 @@
+package db
+
+import (
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestBudgetChecks(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+	food := models.Category{ID: 1, Name: "Food"}
+	rent := models.Category{ID: 2, Name: "Rent", BudgetPeriod: budget.Quarterly}
+
+	// newest first, as many bank statements list them
+	rows := []models.Transaction{
+		{CategoryID: 1, Category: food, Amount: 30, Date: day(time.March, 20)},
+		{CategoryID: 2, Category: rent, Amount: 900, Date: day(time.March, 1)},
+		{CategoryID: 1, Category: food, Amount: 20, Date: day(time.March, 5)},
+		{CategoryID: 1, Category: food, Amount: 10, Date: day(time.February, 27)},
+		{CategoryID: 2, Category: rent, Amount: 900, Date: day(time.February, 1)},
+	}
+	checks := make(budgetChecks)
+	for _, tx := range rows {
+		checks.add(tx)
+	}
+
+	tests := []struct {
+		name     string
+		category models.Category
+		period   time.Time
+		want     float32
+	}{
+		{"newest row of the current month", food, day(time.March, 1), 30},
+		{"older month is checked on its own", food, day(time.February, 1), 10},
+		{"quarter holds both months", rent, day(time.January, 1), 900},
+	}
+	if len(checks) != len(tests) {
+		t.Fatalf("%d periods to check, want %d", len(checks), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, ok := checks[budgetCheck{tt.category.ID, tt.period}]
+			if !ok {
+				t.Fatalf("period %s not checked", tt.period.Format("2006-01-02"))
+			}
+			if tx.Amount != tt.want {
+				t.Errorf("checked with the row of %.2f, want %.2f", tx.Amount, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package db
 
 import (
 	"errors"
-	_ "fmt"
+	"fmt"
 	"peronal_finance_cli_manager/internal/models"
 )
 
@@ -14,6 +14,7 @@ func CreateCategory(name string, budget float32) (*models.Category, error) {
 	cat := models.Category{
 		Name:   name,
 		Budget: budget,
+		Kind:   models.DefaultKind(name),
 	}
 	if err := DB.Create(&cat).Error; err != nil {
 		return nil, err
@@ -45,3 +46,16 @@ func GetAllCategories() ([]models.Category, error) {
 
 	return categories, nil
 }
+
+// SetCategoryKind marks a category as expense or income; empty means
+// expense
+func SetCategoryKind(id uint, kind string) error {
+	switch kind {
+	case "":
+		kind = models.KindExpense
+	case models.KindExpense, models.KindIncome:
+	default:
+		return fmt.Errorf("unknown category kind '%s', use %s or %s", kind, models.KindExpense, models.KindIncome)
+	}
+	return DB.Model(&models.Category{}).Where("id = ?", id).Update("kind", kind).Error
+}
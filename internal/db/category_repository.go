@@ -1,45 +1,126 @@
 package db
 
 import (
+	"peronal_finance_cli_manager/internal/budget"
 	"peronal_finance_cli_manager/internal/models"
-)
+	"time"
 
-func GetBudgetStats() ([]models.BudgetStats, error) {
-	rows, err := DB.Raw(`
-		SELECT 
-			c.name,
-			c.budget,
-			COALESCE(SUM(t.amount), 0) as spent
-		FROM categories c
-		LEFT JOIN transactions t ON t.category_id = c.id
-		GROUP BY c.id
-	`).Rows()
+	"gorm.io/gorm"
+)
 
+// GetBudgetStats returns the envelope of every category for its budget
+// period. offset steps through the periods: 0 is the period holding today,
+// -1 the one before, 1 the next. Categories keep their own period length.
+func GetBudgetStats(today time.Time, offset int) ([]models.BudgetStats, error) {
+	categories, err := GetAllCategories()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	stats := []models.BudgetStats{}
 
-	for rows.Next() {
-		var s models.BudgetStats
-		if err := rows.Scan(&s.CategoryName, &s.Budget, &s.Spent); err != nil {
+	stats := make([]models.BudgetStats, 0, len(categories))
+	for _, c := range categories {
+		period := BudgetPeriod(c, today).Shift(offset)
+		env, err := envelope(DB, c, period)
+		if err != nil {
+			return nil, err
+		}
+		var projected float32
+		forecasting := period.Contains(today) && c.Kind != models.KindIncome
+		if forecasting {
+			p, err := projection(DB, c, period, today)
+			if err != nil {
+				return nil, err
+			}
+			projected = p.Projected()
+		}
+		income, err := periodIncome(DB, period)
+		if err != nil {
 			return nil, err
 		}
-		stats = append(stats, s)
+		stats = append(stats, models.BudgetStats{
+			CategoryName:  c.Name,
+			Kind:          c.Kind,
+			Budget:        float64(env.Budget),
+			Spent:         env.Spent,
+			Carried:       env.Carried,
+			Moved:         env.Moved,
+			Rollover:      c.Rollover,
+			Projected:     projected,
+			HasProjection: forecasting,
+			Percent:       c.BudgetPercent,
+			Group:         c.BudgetGroup,
+			Income:        income,
+			Period:        period.Kind,
+			PeriodLabel:   period.Label(),
+			Start:         period.Start,
+			End:           period.End,
+		})
 	}
 
 	return stats, nil
 }
 
+// UpdateCategoryBudget sets the period a category budget covers, a zero
+// anchor following the calendar, and its amount from the current period
+// on, or with only for the current period. Earlier periods keep the amount
+// they had: the first change records the previous budget as history.
 func UpdateCategoryBudget(
 	id uint,
-	budget float32,
+	amount float32,
+	period string,
+	anchor time.Time,
+	only bool,
 ) error {
-	return DB.
-		Model(&models.Category{}).
-		Where("id = ?", id).
-		Update("budget", budget).
-		Error
+	if period == "" {
+		period = budget.Monthly
+	}
+	if err := budget.Check(period); err != nil {
+		return err
+	}
+
+	return DB.Transaction(func(db *gorm.DB) error {
+		var c models.Category
+		if err := db.First(&c, id).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := db.Model(&models.BudgetAmount{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			base := models.BudgetAmount{CategoryID: id, Amount: c.Budget}
+			if err := db.Create(&base).Error; err != nil {
+				return err
+			}
+		}
+
+		reshaped := c.BudgetPeriod != period || !c.BudgetAnchor.Equal(anchor)
+		c.BudgetPeriod, c.BudgetAnchor = period, anchor
+		current := BudgetPeriod(c, time.Now())
+		entry := models.BudgetAmount{CategoryID: id, Start: current.Start, Amount: amount}
+		updates := map[string]any{"budget_period": period, "budget_anchor": anchor}
+		if reshaped && !c.RolloverFrom.IsZero() {
+			// old balances do not line up with the new periods
+			updates["rollover_from"] = current.Start
+		}
+		if only {
+			entry.End = &current.End
+		} else {
+			// a change from now on replaces what was planned for later
+			if err := db.Where("category_id = ? AND start >= ?", id, current.Start).
+				Delete(&models.BudgetAmount{}).Error; err != nil {
+				return err
+			}
+			updates["budget"] = amount
+		}
+		if err := db.Create(&entry).Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(&models.Category{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		return resetBudgetAlerts(db, id, time.Now())
+	})
 }
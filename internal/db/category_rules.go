@@ -1,22 +1,370 @@
 package db
 
 import (
+	"errors"
+	"fmt"
 	"peronal_finance_cli_manager/internal/models"
-	"regexp"
+	"peronal_finance_cli_manager/internal/rules"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-var categoryRules = []models.CategoryRule{
-	{regexp.MustCompile(`(?i)gas|electric`), "Bills"},
-	{regexp.MustCompile(`(?i)uber|bolt|taxi`), "Transport"},
-	{regexp.MustCompile(`(?i)netflix|spotify`), "Subscriptions"},
+// defaultCategoryRules are created for the categories that exist when the
+// rules table is first created
+var defaultCategoryRules = []struct {
+	Pattern  string
+	Category string
+}{
+	{Pattern: `(?i)gas|electric`, Category: "Bills"},
+	{Pattern: `(?i)uber|bolt|taxi`, Category: "Transport"},
+	{Pattern: `(?i)netflix|spotify`, Category: "Subscriptions"},
+}
+
+// ruleCache holds the rule engine built from the enabled rules. It is
+// built on first use and dropped whenever a rule changes.
+var ruleCache struct {
+	sync.Mutex
+	engine *rules.Engine
+}
+
+func invalidateRuleCache() {
+	ruleCache.Lock()
+	defer ruleCache.Unlock()
+	ruleCache.engine = nil
+}
+
+// RuleEngine returns the engine of the enabled rules. Rules that no longer
+// compile, e.g. edited by hand, are left out.
+func RuleEngine() (*rules.Engine, error) {
+	ruleCache.Lock()
+	defer ruleCache.Unlock()
+	if ruleCache.engine != nil {
+		return ruleCache.engine, nil
+	}
+
+	var list []models.CategoryRule
+	if err := DB.Preload("Category").
+		Where("enabled = ?", true).
+		Order("priority, id").
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+
+	valid := list[:0]
+	for _, rule := range list {
+		if rules.Check(rule) == nil {
+			valid = append(valid, rule)
+		}
+	}
+
+	engine, err := rules.New(valid)
+	if err != nil {
+		return nil, err
+	}
+	ruleCache.engine = engine
+	return engine, nil
+}
+
+// ApplyRules runs the enabled rules on a transaction
+func ApplyRules(tx models.Transaction) (rules.Result, error) {
+	engine, err := RuleEngine()
+	if err != nil {
+		return rules.Result{}, err
+	}
+	return engine.Apply(tx), nil
+}
+
+// seedCategoryRules creates the default rules for existing categories
+func seedCategoryRules(db *gorm.DB) error {
+	for i, def := range defaultCategoryRules {
+		var cat models.Category
+		err := db.Where("name = ?", def.Category).First(&cat).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		rule := models.CategoryRule{
+			Pattern:    def.Pattern,
+			CategoryID: &cat.ID,
+			Priority:   i + 1,
+			Enabled:    true,
+		}
+		if err := db.Create(&rule).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExplainRules evaluates every rule, disabled and broken ones included,
+// against a transaction and tells what each did
+func ExplainRules(tx models.Transaction) ([]rules.Step, rules.Result, error) {
+	list, err := GetCategoryRules()
+	if err != nil {
+		return nil, rules.Result{}, err
+	}
+	steps, res := rules.Explain(list, tx)
+	return steps, res, nil
+}
+
+// AnalyzeRules evaluates the enabled rules against the stored
+// transactions, to find rules that never match and rules shadowed by
+// higher-priority ones. Split parts are left out.
+func AnalyzeRules() ([]rules.Coverage, error) {
+	list, err := GetCategoryRules()
+	if err != nil {
+		return nil, err
+	}
+	var txs []models.Transaction
+	if err := DB.Where("split_group = ?", "").Find(&txs).Error; err != nil {
+		return nil, err
+	}
+	return rules.Analyze(list, txs), nil
+}
+
+// GetCategoryRules returns every rule in priority order
+func GetCategoryRules() ([]models.CategoryRule, error) {
+	var list []models.CategoryRule
+	if err := DB.Preload("Category").Order("priority, id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SaveCategoryRule creates the rule, or updates it when it has an ID. The
+// rule must compile and every category it names must exist; categoryName
+// may be empty for rules that only tag, rename, split or skip. A new rule
+// without a priority goes after the existing ones.
+func SaveCategoryRule(rule *models.CategoryRule, categoryName string) error {
+	rule.CategoryID, rule.Category = nil, nil
+	if categoryName != "" {
+		cat, err := GetCategoryByName(categoryName)
+		if err != nil {
+			return fmt.Errorf("category '%s' not found", categoryName)
+		}
+		rule.CategoryID, rule.Category = &cat.ID, cat
+	}
+	if rule.Match == "" {
+		rule.Match = models.MatchAll
+	}
+
+	if err := rules.Check(*rule); err != nil {
+		return err
+	}
+	for _, a := range rule.Actions {
+		names := []string{a.Value}
+		if a.Type == models.ActionSplit {
+			names = names[:0]
+			for _, s := range a.Splits {
+				names = append(names, s.Category)
+			}
+		} else if a.Type != models.ActionCategory {
+			continue
+		}
+		for _, name := range names {
+			if _, err := GetCategoryByName(name); err != nil {
+				return fmt.Errorf("category '%s' not found", name)
+			}
+		}
+	}
+
+	if rule.ID == 0 && rule.Priority == 0 {
+		var last int
+		if err := DB.Model(&models.CategoryRule{}).
+			Select("COALESCE(MAX(priority), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		rule.Priority = last + 1
+	}
+
+	defer invalidateRuleCache()
+	return DB.Omit("Category").Save(rule).Error
+}
+
+// ImportCategoryRules loads checked rules, in priority order, in one
+// database transaction. With replace every current rule is deleted first.
+// Otherwise a rule named like an existing rule updates it in place and the
+// others are added after the existing rules. Priorities are renumbered
+// 1..n.
+func ImportCategoryRules(list []models.CategoryRule, replace bool) (added, updated int, err error) {
+	defer invalidateRuleCache()
+	err = DB.Transaction(func(db *gorm.DB) error {
+		var existing []models.CategoryRule
+		if replace {
+			if err := db.Where("1 = 1").Delete(&models.CategoryRule{}).Error; err != nil {
+				return err
+			}
+		} else if err := db.Order("priority, id").Find(&existing).Error; err != nil {
+			return err
+		}
+
+		byName := make(map[string]int, len(existing))
+		for i, rule := range existing {
+			if rule.Name != "" {
+				byName[rule.Name] = i
+			}
+		}
+
+		for _, rule := range list {
+			rule.Category = nil
+			if i, ok := byName[rule.Name]; ok && rule.Name != "" {
+				rule.ID = existing[i].ID
+				existing[i] = rule
+				updated++
+				continue
+			}
+			rule.ID = 0
+			existing = append(existing, rule)
+			added++
+		}
+
+		for i := range existing {
+			existing[i].Priority = i + 1
+			if err := db.Omit("Category").Save(&existing[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return added, updated, nil
 }
 
-// RecommendCategory Returns empty string if no match
-func RecommendCategory(description string) string {
-	for _, rule := range categoryRules {
-		if rule.Pattern.MatchString(description) {
-			return rule.Category
+// SetCategoryRuleEnabled turns a rule on or off
+func SetCategoryRuleEnabled(id uint, enabled bool) error {
+	defer invalidateRuleCache()
+	return DB.Model(&models.CategoryRule{}).Where("id = ?", id).Update("enabled", enabled).Error
+}
+
+// DeleteCategoryRule removes a rule
+func DeleteCategoryRule(id uint) error {
+	defer invalidateRuleCache()
+	return DB.Delete(&models.CategoryRule{}, id).Error
+}
+
+// MoveCategoryRule moves a rule up (delta < 0) or down (delta > 0) in the
+// priority order. Priorities are renumbered 1..n on the way.
+func MoveCategoryRule(id uint, delta int) error {
+	defer invalidateRuleCache()
+	return DB.Transaction(func(db *gorm.DB) error {
+		var list []models.CategoryRule
+		if err := db.Order("priority, id").Find(&list).Error; err != nil {
+			return err
+		}
+
+		from := -1
+		for i, rule := range list {
+			if rule.ID == id {
+				from = i
+			}
+		}
+		if from < 0 {
+			return gorm.ErrRecordNotFound
+		}
+		to := min(max(from+delta, 0), len(list)-1)
+		list[from], list[to] = list[to], list[from]
+
+		for i, rule := range list {
+			if err := db.Model(&models.CategoryRule{}).
+				Where("id = ?", rule.ID).
+				Update("priority", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// reapplyRules runs the enabled rules over the transactions scope selects
+// and saves what they change: category, payee, tags and the transfer
+// mark. Splitting and skipping only happen on entry and import. It returns
+// how many transactions changed.
+func reapplyRules(scope func(*gorm.DB) *gorm.DB) (int, error) {
+	engine, err := RuleEngine()
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	checks := make(budgetChecks)
+	err = DB.Transaction(func(db *gorm.DB) error {
+		var txs []models.Transaction
+		if err := db.Scopes(scope).
+			Preload("Category").
+			Where("split_group = ?", "").
+			Find(&txs).Error; err != nil {
+			return err
+		}
+
+		var changes []models.AuditChange
+		categories := make(map[string]models.Category)
+		// spending that left a category, whose alerts may no longer hold
+		left := make(map[uint][]time.Time)
+		for _, tx := range txs {
+			res := engine.Apply(tx)
+			next := res.Transaction
+
+			updates := map[string]any{}
+			change := func(column, field, from, to string, value any) {
+				updates[column] = value
+				changes = append(changes, models.AuditChange{
+					TransactionID: tx.ID,
+					Field:         field,
+					From:          from,
+					To:            to,
+				})
+			}
+			if name := next.Category.Name; name != "" && name != tx.Category.Name {
+				cat, ok := categories[name]
+				if !ok {
+					if err := db.Where("name = ?", name).First(&cat).Error; err != nil {
+						return fmt.Errorf("category '%s' not found", name)
+					}
+					categories[name] = cat
+				}
+				change("category_id", "category", tx.Category.Name, name, cat.ID)
+				next.CategoryID, next.Category = cat.ID, cat
+			}
+			if next.Payee != tx.Payee {
+				change("payee", "payee", tx.Payee, next.Payee, next.Payee)
+			}
+			if next.Tags != tx.Tags {
+				change("tags", "tags", tx.Tags, next.Tags, next.Tags)
+			}
+			if next.IsTransfer != tx.IsTransfer {
+				change("is_transfer", "transfer", fmt.Sprint(tx.IsTransfer), fmt.Sprint(next.IsTransfer), next.IsTransfer)
+			}
+			if len(updates) == 0 {
+				continue
+			}
+
+			if err := db.Model(&models.Transaction{}).Where("id = ?", tx.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+			if _, moved := updates["category_id"]; moved || next.IsTransfer && !tx.IsTransfer {
+				left[tx.CategoryID] = append(left[tx.CategoryID], tx.Date)
+			}
+			if _, moved := updates["category_id"]; moved && !next.IsTransfer {
+				checks.add(next)
+			}
+			changed++
+		}
+		if err := resetBudgetAlertsOf(db, left); err != nil {
+			return err
 		}
+		return audit(db, AuditApplyRules, fmt.Sprintf("rules changed %d transaction(s)", changed), changes)
+	})
+	if err != nil {
+		return changed, err
 	}
-	return ""
+	// the spending that arrived may take the new categories over budget
+	checks.run(DB)
+	return changed, nil
 }
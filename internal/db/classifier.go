@@ -0,0 +1,75 @@
+package db
+
+import (
+	"peronal_finance_cli_manager/internal/classifier"
+	"peronal_finance_cli_manager/internal/models"
+	"sync"
+)
+
+// learned holds the category classifier. It is trained from the stored
+// transactions on first use, then kept up to date as transactions are
+// added and categorized; bulk changes drop it to be trained again.
+var learned struct {
+	sync.Mutex
+	model *classifier.Model
+}
+
+func invalidateClassifier() {
+	learned.Lock()
+	defer learned.Unlock()
+	learned.model = nil
+}
+
+// categoryClassifier returns the trained classifier
+func categoryClassifier() (*classifier.Model, error) {
+	learned.Lock()
+	defer learned.Unlock()
+	if learned.model != nil {
+		return learned.model, nil
+	}
+
+	var txs []models.Transaction
+	if err := DB.Preload("Category").Find(&txs).Error; err != nil {
+		return nil, err
+	}
+	model := classifier.New()
+	for _, tx := range txs {
+		if tx.Category.Name != UncategorizedCategory {
+			model.Learn(tx, tx.Category.Name)
+		}
+	}
+	learned.model = model
+	return model, nil
+}
+
+// trained returns the classifier when it was already trained, so updates
+// do not train it just to change it
+func trained() *classifier.Model {
+	learned.Lock()
+	defer learned.Unlock()
+	return learned.model
+}
+
+// learnCategory teaches the classifier a categorized transaction
+func learnCategory(tx models.Transaction, category string) {
+	if model := trained(); model != nil && category != UncategorizedCategory {
+		model.Learn(tx, category)
+	}
+}
+
+// forgetCategory removes a transaction the classifier learned
+func forgetCategory(tx models.Transaction, category string) {
+	if model := trained(); model != nil && category != UncategorizedCategory {
+		model.Forget(tx, category)
+	}
+}
+
+// SuggestCategories returns up to n categories for a transaction, learned
+// from the categorized history, most likely first
+func SuggestCategories(tx models.Transaction, n int) ([]classifier.Suggestion, error) {
+	model, err := categoryClassifier()
+	if err != nil {
+		return nil, err
+	}
+	return model.Predict(tx, n), nil
+}
@@ -3,22 +3,28 @@ package db
 import (
 	"log"
 	"os"
+	"path/filepath"
+
+	"peronal_finance_cli_manager/internal/models"
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// DataDir holds the database and the optional configuration files
+const DataDir = "data"
+
 var DB *gorm.DB
 
 func Connect() {
-	if _, err := os.Stat("data"); os.IsNotExist(err) {
-		if err := os.MkdirAll("data", os.ModePerm); err != nil {
+	if _, err := os.Stat(DataDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(DataDir, os.ModePerm); err != nil {
 			log.Fatalf("Failed to create data folder: %v", err)
 		}
 	}
 
-	db, err := gorm.Open(sqlite.Open("data/app.db"), &gorm.Config{
+	db, err := gorm.Open(sqlite.Open(filepath.Join(DataDir, "app.db")), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
@@ -27,3 +33,43 @@ func Connect() {
 	}
 	DB = db
 }
+
+// Migrate creates or updates the tables of every model
+func Migrate() error {
+	seedRules := !DB.Migrator().HasTable(&models.CategoryRule{})
+	addKinds := DB.Migrator().HasTable(&models.Category{}) && !DB.Migrator().HasColumn(&models.Category{}, "Kind")
+
+	if err := DB.AutoMigrate(
+		&models.Category{},
+		&models.Transaction{},
+		&models.DuplicateDismissal{},
+		&models.ImportBatch{},
+		&models.CategoryRule{},
+		&models.AuditEntry{},
+		&models.Merchant{},
+		&models.MerchantAlias{},
+		&models.BudgetAmount{},
+		&models.EnvelopeTransfer{},
+		&models.Setting{},
+		&models.BudgetAssignment{},
+		&models.BudgetAlert{},
+		&models.Goal{},
+		&models.GoalContribution{},
+	); err != nil {
+		return err
+	}
+
+	if addKinds {
+		// income was told apart by name before categories had a kind
+		if err := DB.Model(&models.Category{}).
+			Where("name = ?", models.IncomeCategory).
+			Update("kind", models.KindIncome).Error; err != nil {
+			return err
+		}
+	}
+
+	if seedRules {
+		return seedCategoryRules(DB)
+	}
+	return nil
+}
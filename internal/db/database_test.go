@@ -0,0 +1,41 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// resetCaches drops what was built from another database
+func resetCaches() {
+	invalidateRuleCache()
+	invalidateMerchants()
+	invalidateClassifier()
+}
+
+// openTestDB points DB at a fresh, migrated database for the test
+func openTestDB(t *testing.T) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := DB
+	DB = db
+	resetCaches()
+	t.Cleanup(func() {
+		DB = previous
+		resetCaches()
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	if err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+)
+
+// FindPossibleDuplicates returns pairs of transactions with the same amount
+// booked at most `days` days apart, leaving out pairs already dismissed.
+func FindPossibleDuplicates(days int) ([]models.DuplicatePair, error) {
+	rows, err := DB.Raw(`
+		SELECT a.id, b.id
+		FROM transactions a
+		JOIN transactions b
+			ON a.id < b.id
+			AND a.amount = b.amount
+			AND ABS(julianday(a.date) - julianday(b.date)) <= ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM duplicate_dismissals d
+			WHERE d.first_id = a.id AND d.second_id = b.id
+		)
+		ORDER BY a.date DESC
+	`, days).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type idPair struct{ first, second uint }
+	var ids []idPair
+	for rows.Next() {
+		var p idPair
+		if err := rows.Scan(&p.first, &p.second); err != nil {
+			return nil, err
+		}
+		ids = append(ids, p)
+	}
+
+	pairs := make([]models.DuplicatePair, 0, len(ids))
+	for _, p := range ids {
+		var first, second models.Transaction
+		if err := DB.Preload("Category").First(&first, p.first).Error; err != nil {
+			return nil, err
+		}
+		if err := DB.Preload("Category").First(&second, p.second).Error; err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, models.DuplicatePair{First: first, Second: second})
+	}
+
+	return pairs, nil
+}
+
+// DismissDuplicate marks a pair as reviewed so it is no longer reported
+func DismissDuplicate(firstID, secondID uint) error {
+	return DB.Create(&models.DuplicateDismissal{
+		FirstID:  firstID,
+		SecondID: secondID,
+	}).Error
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// movedIn sums the money moved into a category within a budget period,
+// less what was moved out
+func movedIn(db *gorm.DB, categoryID uint, period budget.Period) (float32, error) {
+	var moved float32
+	err := db.Model(&models.EnvelopeTransfer{}).
+		Where("? IN (from_category_id, to_category_id)", categoryID).
+		Where("date(date) >= ? AND date(date) < ?",
+			period.Start.Format("2006-01-02"), period.End.Format("2006-01-02")).
+		Select("COALESCE(SUM(CASE WHEN to_category_id = ? THEN amount ELSE -amount END), 0)", categoryID).
+		Row().Scan(&moved)
+	return moved, err
+}
+
+// envelopeAt fills the envelope of a category for a period, leaving what
+// was carried in to the caller
+func envelopeAt(db *gorm.DB, category models.Category, period budget.Period, carried float32) (budget.Envelope, error) {
+	env := budget.Envelope{Period: period, Carried: carried}
+	var err error
+	if env.Budget, err = budgetAmount(db, category, period); err != nil {
+		return env, err
+	}
+	if env.Moved, err = movedIn(db, category.ID, period); err != nil {
+		return env, err
+	}
+	env.Spent, err = spentIn(db, category.ID, period)
+	return env, err
+}
+
+// envelope returns the envelope of a category for a period. With rollover
+// on, what is left of every period since rollover was switched on carries
+// into the next one.
+func envelope(db *gorm.DB, category models.Category, period budget.Period) (budget.Envelope, error) {
+	var carried float32
+	if category.Rollover != "" && category.Rollover != budget.RolloverNone && !category.RolloverFrom.IsZero() {
+		for p := BudgetPeriod(category, category.RolloverFrom); p.Start.Before(period.Start); p = p.Shift(1) {
+			env, err := envelopeAt(db, category, p, carried)
+			if err != nil {
+				return env, err
+			}
+			carried = env.Carry(category.Rollover)
+		}
+	}
+	return envelopeAt(db, category, period, carried)
+}
+
+// GetEnvelope returns the envelope of a category for the budget period
+// holding date
+func GetEnvelope(category models.Category, date time.Time) (budget.Envelope, error) {
+	return envelope(DB, category, BudgetPeriod(category, date))
+}
+
+// SetCategoryRollover sets what happens to the money left in a category at
+// the end of a period. Balances roll over from the current period on; a
+// category that already rolls over keeps its balance.
+func SetCategoryRollover(id uint, mode string) error {
+	if mode == "" {
+		mode = budget.RolloverNone
+	}
+	if err := budget.CheckRollover(mode); err != nil {
+		return err
+	}
+
+	return DB.Transaction(func(db *gorm.DB) error {
+		var c models.Category
+		if err := db.First(&c, id).Error; err != nil {
+			return err
+		}
+		if c.Rollover == mode {
+			return nil
+		}
+
+		updates := map[string]any{"rollover": mode}
+		switch {
+		case mode == budget.RolloverNone:
+			updates["rollover_from"] = time.Time{}
+		case c.RolloverFrom.IsZero():
+			updates["rollover_from"] = BudgetPeriod(c, time.Now()).Start
+		}
+		if err := db.Model(&models.Category{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		return resetBudgetAlerts(db, id, time.Now())
+	})
+}
+
+// MoveBudget moves money from one category envelope to another for the
+// budget period holding date; the reason is kept with the move
+func MoveBudget(from, to string, amount float32, reason string, date time.Time) (*models.EnvelopeTransfer, error) {
+	reason = strings.TrimSpace(reason)
+	switch {
+	case from == to:
+		return nil, errors.New("pick two different categories")
+	case amount <= 0:
+		return nil, errors.New("the amount to move must be positive")
+	case reason == "":
+		return nil, errors.New("give a reason for the move")
+	}
+
+	source, err := GetCategoryByName(from)
+	if err != nil {
+		return nil, fmt.Errorf("category '%s' not found", from)
+	}
+	target, err := GetCategoryByName(to)
+	if err != nil {
+		return nil, fmt.Errorf("category '%s' not found", to)
+	}
+
+	move := models.EnvelopeTransfer{
+		Date:           date,
+		FromCategoryID: source.ID,
+		ToCategoryID:   target.ID,
+		Amount:         amount,
+		Reason:         reason,
+	}
+	err = DB.Transaction(func(db *gorm.DB) error {
+		if err := db.Create(&move).Error; err != nil {
+			return err
+		}
+		return resetBudgetAlerts(db, target.ID, date)
+	})
+	if err != nil {
+		return nil, err
+	}
+	move.FromCategory, move.ToCategory = *source, *target
+	return &move, nil
+}
+
+// GetEnvelopeTransfers returns the money moved between envelopes from
+// start up to end, newest first
+func GetEnvelopeTransfers(start, end time.Time) ([]models.EnvelopeTransfer, error) {
+	var moves []models.EnvelopeTransfer
+	err := DB.Preload("FromCategory").Preload("ToCategory").
+		Where("date(date) >= ? AND date(date) < ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("date DESC, id DESC").
+		Find(&moves).Error
+	return moves, err
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/forecast"
+	"peronal_finance_cli_manager/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// projection forecasts the spending of a category by the end of a budget
+// period from its transactions up to today
+func projection(db *gorm.DB, category models.Category, period budget.Period, today time.Time) (forecast.Projection, error) {
+	var history []models.Transaction
+	err := db.Where("category_id = ?", category.ID).
+		Where("is_transfer = ?", false).
+		Where("date(date) >= ? AND date(date) <= ?",
+			forecast.HistoryStart(period).Format("2006-01-02"), today.Format("2006-01-02")).
+		Order("date").
+		Find(&history).Error
+	if err != nil {
+		return forecast.Projection{}, err
+	}
+	return forecast.Project(period, today, history), nil
+}
+
+// GetProjection forecasts the spending of a category by the end of its
+// budget period holding today
+func GetProjection(category models.Category, today time.Time) (forecast.Projection, error) {
+	return projection(DB, category, BudgetPeriod(category, today), today)
+}
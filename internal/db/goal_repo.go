@@ -0,0 +1,192 @@
+package db
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"peronal_finance_cli_manager/internal/goal"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CreateGoal adds a savings goal, linked to the account or the category
+// when one is given. A goal without a start date starts today.
+func CreateGoal(g *models.Goal, categoryName string) error {
+	g.Name = strings.TrimSpace(g.Name)
+	g.Account = strings.TrimSpace(g.Account)
+	categoryName = strings.TrimSpace(categoryName)
+	if g.StartDate.IsZero() {
+		g.StartDate = time.Now()
+	}
+	switch {
+	case g.Name == "":
+		return errors.New("goal name is empty")
+	case g.Target <= 0:
+		return errors.New("the target amount must be positive")
+	case !g.TargetDate.After(g.StartDate):
+		return errors.New("the target date must be after the start")
+	case g.Account != "" && categoryName != "":
+		return errors.New("link the goal to an account or a category, not both")
+	}
+	if categoryName != "" {
+		category, err := GetCategoryByName(categoryName)
+		if err != nil {
+			return fmt.Errorf("category '%s' not found", categoryName)
+		}
+		g.CategoryID = &category.ID
+	}
+	return DB.Omit("Category").Create(g).Error
+}
+
+// DeleteGoal deletes a goal with its contributions
+func DeleteGoal(id uint) error {
+	return DB.Transaction(func(db *gorm.DB) error {
+		if err := db.Where("goal_id = ?", id).Delete(&models.GoalContribution{}).Error; err != nil {
+			return err
+		}
+		return db.Delete(&models.Goal{}, id).Error
+	})
+}
+
+// AddContribution records money put aside for a goal and sends an alert
+// when it reaches a milestone
+func AddContribution(goalID uint, amount float32, date time.Time, note string) error {
+	if amount == 0 {
+		return errors.New("the contribution cannot be zero")
+	}
+	c := models.GoalContribution{GoalID: goalID, Amount: amount, Date: date, Note: strings.TrimSpace(note)}
+	if err := DB.Create(&c).Error; err != nil {
+		return err
+	}
+	// a milestone alert that could not be sent is tried again later
+	_ = CheckGoals()
+	return nil
+}
+
+// savedFor adds up the contributions of a goal and, from its start on, the
+// transactions of its linked category, or the net of its linked account:
+// income and transfers in, less what was spent or moved out of it
+func savedFor(db *gorm.DB, g models.Goal) (float32, error) {
+	var saved float32
+	if err := db.Model(&models.GoalContribution{}).
+		Where("goal_id = ?", g.ID).
+		Select("COALESCE(SUM(amount), 0)").
+		Row().Scan(&saved); err != nil {
+		return 0, err
+	}
+	if g.CategoryID == nil && g.Account == "" {
+		return saved, nil
+	}
+
+	linked := db.Model(&models.Transaction{}).
+		Where("date(transactions.date) >= ?", g.StartDate.Format("2006-01-02"))
+	if g.CategoryID != nil {
+		linked = linked.Where("transactions.category_id = ?", *g.CategoryID).
+			Select("COALESCE(SUM(transactions.amount), 0)")
+	} else {
+		// amounts are positive both ways: the kind of the category tells
+		// money in from money out
+		linked = linked.Joins("JOIN categories c ON c.id = transactions.category_id").
+			Where("transactions.account = ?", g.Account).
+			Select("COALESCE(SUM(CASE WHEN c.kind = ? THEN transactions.amount ELSE -transactions.amount END), 0)",
+				models.KindIncome)
+	}
+	var transactions float32
+	if err := linked.Row().Scan(&transactions); err != nil {
+		return 0, err
+	}
+	return saved + transactions, nil
+}
+
+// goalStatuses evaluates every goal, sooner target dates first
+func goalStatuses(db *gorm.DB, today time.Time) ([]models.GoalStatus, error) {
+	var goals []models.Goal
+	if err := db.Preload("Category").
+		Preload("Contributions", func(db *gorm.DB) *gorm.DB { return db.Order("date DESC, id DESC") }).
+		Order("target_date, id").
+		Find(&goals).Error; err != nil {
+		return nil, err
+	}
+
+	statuses := make([]models.GoalStatus, 0, len(goals))
+	for _, g := range goals {
+		saved, err := savedFor(db, g)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, goal.Evaluate(g, saved, today))
+	}
+	return statuses, nil
+}
+
+// GetGoalStatuses returns every goal with its progress as of today
+func GetGoalStatuses(today time.Time) ([]models.GoalStatus, error) {
+	return goalStatuses(DB, today)
+}
+
+// CheckGoals sends an alert for every goal that reached a new milestone.
+// A goal that dropped back below a milestone, e.g. after a contribution
+// was deleted, alerts again when it reaches it once more. It returns the
+// first alert that could not be sent; the other goals are still checked.
+func CheckGoals() error {
+	statuses, err := goalStatuses(DB, time.Now())
+	if err != nil {
+		return err
+	}
+	var failed error
+	for _, s := range statuses {
+		milestone := goal.Milestone(s.Progress())
+		if milestone == s.Goal.Milestone {
+			continue
+		}
+		// record the milestone only once the alert is out, so a failed
+		// publish is tried again
+		if milestone > s.Goal.Milestone {
+			if err := PublishGoalMilestone(s, milestone); err != nil {
+				failed = cmp.Or(failed, err)
+				continue
+			}
+		}
+		if err := DB.Model(&models.Goal{}).Where("id = ?", s.Goal.ID).
+			Update("milestone", milestone).Error; err != nil {
+			return err
+		}
+	}
+	return failed
+}
+
+// DeleteContribution deletes a contribution to a goal
+func DeleteContribution(id uint) error {
+	if err := DB.Delete(&models.GoalContribution{}, id).Error; err != nil {
+		return err
+	}
+	_ = CheckGoals()
+	return nil
+}
+
+// PublishGoalMilestone notifies the alert worker that a goal reached a
+// milestone
+func PublishGoalMilestone(s models.GoalStatus, milestone int) error {
+	headline := fmt.Sprintf("🎯 %s is %d%% saved", s.Goal.Name, milestone)
+	if milestone >= 100 {
+		headline = fmt.Sprintf("🎉 %s reached its target", s.Goal.Name)
+	}
+	msg := fmt.Sprintf(
+		"%s\nSaved: %.2f / %.2f, target date %s\n",
+		headline,
+		s.Saved,
+		s.Goal.Target,
+		s.Goal.TargetDate.Format("2006-01-02"),
+	)
+	if milestone < 100 {
+		pace := "behind"
+		if s.OnTrack {
+			pace = "on track"
+		}
+		msg += fmt.Sprintf("Needed per month: %.2f (%s)", s.Required, pace)
+	}
+	return publishAlert(AlertGoalMilestone, msg)
+}
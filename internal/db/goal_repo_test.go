@@ -0,0 +1,74 @@
+package db
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestSavedFor(t *testing.T) {
+	openTestDB(t)
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	category := func(name, kind string) uint {
+		c := models.Category{Name: name, Kind: kind}
+		if err := DB.Create(&c).Error; err != nil {
+			t.Fatal(err)
+		}
+		return c.ID
+	}
+	income := category("Income", models.KindIncome)
+	groceries := category("Groceries", models.KindExpense)
+	transfers := category("Transfers", models.KindExpense)
+	vacation := category("Vacation", models.KindExpense)
+
+	txs := []models.Transaction{
+		{CategoryID: income, Amount: 500, Date: start.AddDate(0, 0, 1), Account: "Savings"},
+		{CategoryID: income, Amount: 200, Date: start.AddDate(0, 0, 2), Account: "Savings", IsTransfer: true},
+		// spending and moving money out of the account is not saving
+		{CategoryID: groceries, Amount: 120, Date: start.AddDate(0, 0, 3), Account: "Savings"},
+		{CategoryID: transfers, Amount: 100, Date: start.AddDate(0, 0, 4), Account: "Savings", IsTransfer: true},
+		// other accounts and days before the goal started do not count
+		{CategoryID: income, Amount: 900, Date: start.AddDate(0, 0, 1), Account: "Checking"},
+		{CategoryID: income, Amount: 300, Date: start.AddDate(0, 0, -1), Account: "Savings"},
+		{CategoryID: vacation, Amount: 80, Date: start.AddDate(0, 0, 5), Account: "Checking"},
+		{CategoryID: vacation, Amount: 40, Date: start.AddDate(0, 0, -2), Account: "Checking"},
+	}
+	if err := DB.Omit("Category").Create(&txs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		goal models.Goal
+		want float32
+	}{
+		{"account nets money in against money out", models.Goal{Account: "Savings"}, 50 + 500 + 200 - 120 - 100},
+		{"category counts its transactions", models.Goal{CategoryID: &vacation}, 50 + 80},
+		{"unlinked counts contributions only", models.Goal{}, 50},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.goal
+			g.Name = tt.name
+			g.Target = 1000
+			g.StartDate = start
+			g.TargetDate = start.AddDate(1, 0, 0)
+			if err := DB.Omit("Category").Create(&g).Error; err != nil {
+				t.Fatal(err)
+			}
+			contribution := models.GoalContribution{GoalID: g.ID, Amount: 50, Date: start.AddDate(0, 0, i)}
+			if err := DB.Create(&contribution).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := savedFor(DB, g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("savedFor = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
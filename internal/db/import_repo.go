@@ -0,0 +1,337 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"peronal_finance_cli_manager/internal/merchant"
+	"peronal_finance_cli_manager/internal/models"
+	"peronal_finance_cli_manager/internal/rules"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultImportBudget is the budget given to categories created by an import
+const DefaultImportBudget = 10000
+
+// UncategorizedCategory receives imported rows nothing could categorize
+const UncategorizedCategory = "Uncategorized"
+
+// importChunkSize is how many rows are checked and inserted per statement
+const importChunkSize = 500
+
+// CommitImport stores the rows of an import batch inside a single database
+// transaction: either every row is written or, on a database error or when
+// ctx is cancelled, none. Rows are checked and inserted in chunks, and
+// progress, when set, is called after each chunk with the rows done so far
+// and total (0 when unknown).
+// Every row must carry its import identity; already imported rows are
+// skipped. Payees are normalized to their merchant first. The rules then
+// run on every new row: they may skip it, tag it, rename its payee, mark
+// it as a transfer or split it; a row that carries its own category or is
+// already a part of a split is not split. Rows without a category get their
+// merchant's default category, the one the rules assign, or
+// Uncategorized. Missing categories are created with the budget the row
+// carries, or DefaultImportBudget, and its kind, and belong to the batch,
+// so reverting it removes them again. verbatim stores the rows as they
+// are, without merchants or rules, e.g. to load a journal the app wrote.
+// The batch counts are added to whatever the caller already put in them.
+func CommitImport(
+	ctx context.Context,
+	batch *models.ImportBatch,
+	rows iter.Seq[models.Transaction],
+	total int,
+	verbatim bool,
+	progress func(done, total int),
+) (models.ImportSummary, error) {
+	var summary models.ImportSummary
+	checks := make(budgetChecks)
+
+	// load the rules before the transaction takes the write lock
+	engine, err := RuleEngine()
+	if err != nil {
+		return summary, err
+	}
+	merchants, err := merchantMatcher()
+	if err != nil {
+		return summary, err
+	}
+
+	err = DB.Transaction(func(db *gorm.DB) error {
+		if err := db.Create(batch).Error; err != nil {
+			return err
+		}
+
+		categories := make(map[string]models.Category)
+		// a new category takes the budget and kind the row carries
+		resolve := func(name string, carried models.Category) (models.Category, error) {
+			if cat, ok := categories[name]; ok {
+				return cat, nil
+			}
+			var cat models.Category
+			err := db.Where("name = ?", name).First(&cat).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				cat = models.Category{
+					Name:    name,
+					Budget:  cmp.Or(carried.Budget, DefaultImportBudget),
+					Kind:    cmp.Or(carried.Kind, models.DefaultKind(name)),
+					BatchID: &batch.ID,
+				}
+				err = db.Create(&cat).Error
+			}
+			if err != nil {
+				return cat, err
+			}
+			categories[name] = cat
+			return cat, nil
+		}
+
+		done := 0
+		flush := func(chunk []models.Transaction) error {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+
+			ids := make([]string, 0, len(chunk))
+			for _, imported := range chunk {
+				ids = append(ids, imported.ImportID)
+			}
+			existing, err := importedIDs(db, ids)
+			if err != nil {
+				return err
+			}
+
+			toCreate := make([]models.Transaction, 0, len(chunk))
+			for _, imported := range chunk {
+				if imported.ImportID == "" {
+					summary.Failed++
+					summary.Errors = append(summary.Errors, "row without import identity")
+					continue
+				}
+				if existing[imported.ImportID] {
+					summary.Skipped++
+					continue
+				}
+				existing[imported.ImportID] = true
+
+				parts := []models.Transaction{imported}
+				if !verbatim {
+					var skip bool
+					if parts, skip = importParts(engine, merchants, imported); skip {
+						summary.Skipped++
+						continue
+					}
+				}
+				for _, part := range parts {
+					if part.Category.Name == "" {
+						part.Category.Name = UncategorizedCategory
+					}
+					carried := imported.Category
+					if part.Category.Name != carried.Name {
+						// only the row's own category takes its kind
+						carried.Kind = ""
+					}
+					cat, err := resolve(part.Category.Name, carried)
+					if err != nil {
+						return err
+					}
+
+					y, m, d := part.Date.Date()
+					part.ID = 0
+					part.CategoryID = cat.ID
+					part.Date = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+					part.BatchID = &batch.ID
+					part.Category = cat
+					toCreate = append(toCreate, part)
+				}
+			}
+
+			if len(toCreate) > 0 {
+				if err := db.Omit("Category").CreateInBatches(&toCreate, importChunkSize).Error; err != nil {
+					return err
+				}
+			}
+			for _, tx := range toCreate {
+				checks.add(tx)
+			}
+			summary.Imported += len(toCreate)
+
+			done += len(chunk)
+			if progress != nil {
+				progress(done, total)
+			}
+			return nil
+		}
+
+		chunk := make([]models.Transaction, 0, importChunkSize)
+		for tx := range rows {
+			chunk = append(chunk, tx)
+			if len(chunk) < importChunkSize {
+				continue
+			}
+			if err := flush(chunk); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+		}
+		if len(chunk) > 0 {
+			if err := flush(chunk); err != nil {
+				return err
+			}
+		}
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		summary.BatchID = batch.ID
+		batch.Imported += summary.Imported
+		batch.Skipped += summary.Skipped
+		batch.Failed += summary.Failed
+		return db.Save(batch).Error
+	})
+	if err != nil {
+		return models.ImportSummary{}, err
+	}
+	if summary.Imported > 0 {
+		invalidateClassifier()
+	}
+
+	// check every budget period the rows went into once, not once per row
+	checks.run(DB)
+	_ = CheckGoals()
+
+	return summary, nil
+}
+
+// importParts normalizes the payee of an imported row and runs the rules
+// on it, returning the rows to store, or skip when a rule leaves it out
+func importParts(engine *rules.Engine, merchants *merchant.Matcher, imported models.Transaction) ([]models.Transaction, bool) {
+	m, _ := merchants.Normalize(&imported)
+	if imported.Category.Name == "" {
+		imported.Category.Name = merchantCategory(m)
+	}
+
+	res := engine.Apply(imported)
+	if res.Skip {
+		return nil, true
+	}
+	if res.Splits != nil && imported.Category.Name == "" && imported.SplitGroup == "" {
+		return res.Splits, false
+	}
+
+	// the row's own category wins over the rules
+	tx := res.Transaction
+	if imported.Category.Name != "" {
+		tx.Category = imported.Category
+	}
+	return []models.Transaction{tx}, false
+}
+
+// ImportedIDs returns which of the given import identities already exist
+func ImportedIDs(ids []string) (map[string]bool, error) {
+	return importedIDs(DB, ids)
+}
+
+func importedIDs(db *gorm.DB, ids []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for start := 0; start < len(ids); start += importChunkSize {
+		end := min(start+importChunkSize, len(ids))
+
+		var found []string
+		if err := db.Model(&models.Transaction{}).
+			Where("import_id IN ?", ids[start:end]).
+			Pluck("import_id", &found).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range found {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+// TransactionImported reports whether a transaction with the given import identity exists
+func TransactionImported(importID string) (bool, error) {
+	var count int64
+	err := DB.Model(&models.Transaction{}).
+		Where("import_id = ?", importID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func GetImportBatches() ([]models.ImportBatch, error) {
+	var batches []models.ImportBatch
+	if err := DB.Order("id DESC").Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// RevertImportBatch deletes every transaction of a batch together with the
+// categories the batch created, as long as no transaction, rule or merchant
+// uses them.
+func RevertImportBatch(id uint) error {
+	defer invalidateClassifier()
+	return DB.Transaction(func(db *gorm.DB) error {
+		var batch models.ImportBatch
+		if err := db.First(&batch, id).Error; err != nil {
+			return err
+		}
+		if batch.RevertedAt != nil {
+			return fmt.Errorf("import #%d was already reverted", id)
+		}
+
+		var removed []models.Transaction
+		if err := db.Where("batch_id = ?", id).Find(&removed).Error; err != nil {
+			return err
+		}
+		if err := db.Where("batch_id = ?", id).Delete(&models.Transaction{}).Error; err != nil {
+			return err
+		}
+		left := make(map[uint][]time.Time)
+		for _, tx := range removed {
+			left[tx.CategoryID] = append(left[tx.CategoryID], tx.Date)
+		}
+		if err := resetBudgetAlertsOf(db, left); err != nil {
+			return err
+		}
+
+		err := db.Where("batch_id = ?", id).
+			Where("NOT EXISTS (SELECT 1 FROM transactions t WHERE t.category_id = categories.id)").
+			Where("NOT EXISTS (SELECT 1 FROM category_rules r WHERE r.category_id = categories.id)").
+			Where("NOT EXISTS (SELECT 1 FROM merchants m WHERE m.category_id = categories.id)").
+			Where("NOT EXISTS (SELECT 1 FROM budget_amounts b WHERE b.category_id = categories.id)").
+			Where("NOT EXISTS (SELECT 1 FROM envelope_transfers e WHERE categories.id IN (e.from_category_id, e.to_category_id))").
+			Where("NOT EXISTS (SELECT 1 FROM budget_assignments a WHERE a.category_id = categories.id)").
+			Where("NOT EXISTS (SELECT 1 FROM goals g WHERE g.category_id = categories.id)").
+			Delete(&models.Category{}).Error
+		if err != nil {
+			return err
+		}
+		// categories still in use stay, but no longer belong to the batch
+		if err := db.Model(&models.Category{}).
+			Where("batch_id = ?", id).
+			Update("batch_id", nil).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		batch.RevertedAt = &now
+		return db.Save(&batch).Error
+	})
+}
+
+// PublishImportCompleted notifies the alert worker that a file was imported
+func PublishImportCompleted(fileName string, summary models.ImportSummary) error {
+	msg := fmt.Sprintf(
+		"📥 Import completed for %s\nImported: %d\nSkipped (already imported): %d\nFailed: %d",
+		fileName,
+		summary.Imported,
+		summary.Skipped,
+		summary.Failed,
+	)
+	return publishAlert(AlertImportCompleted, msg)
+}
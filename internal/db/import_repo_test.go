@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"peronal_finance_cli_manager/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCommitImportKeepsSplits(t *testing.T) {
+	openTestDB(t)
+
+	rule := models.CategoryRule{
+		Name:       "Split the market",
+		Enabled:    true,
+		Match:      models.MatchAll,
+		Conditions: []models.RuleCondition{{Field: models.FieldPayee, Op: models.OpContains, Value: "market"}},
+		Actions: []models.RuleAction{{Type: models.ActionSplit, Splits: []models.RuleSplit{
+			{Category: "Food", Percent: 50},
+			{Category: "Home", Percent: 50},
+		}}},
+	}
+	if err := DB.Create(&rule).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	type row struct {
+		category string
+		amount   float32
+		importID string
+		group    string
+	}
+	tests := []struct {
+		name string
+		rows []models.Transaction
+		want []row
+	}{
+		{
+			name: "split journal entry keeps its parts",
+			rows: []models.Transaction{
+				{Category: models.Category{Name: "Food"}, Amount: 30, Date: day, Payee: "Market", ImportID: "abc", SplitGroup: "abc"},
+				{Category: models.Category{Name: "Home"}, Amount: 10, Date: day, Payee: "Market", ImportID: "abc/2", SplitGroup: "abc"},
+			},
+			want: []row{{"Food", 30, "abc", "abc"}, {"Home", 10, "abc/2", "abc"}},
+		},
+		{
+			name: "row with its own category is not split",
+			rows: []models.Transaction{
+				{Category: models.Category{Name: "Food"}, Amount: 20, Date: day, Payee: "Market", ImportID: "def"},
+			},
+			want: []row{{"Food", 20, "def", ""}},
+		},
+		{
+			name: "row without a category is split by the rule",
+			rows: []models.Transaction{
+				{Amount: 40, Date: day, Payee: "Market", ImportID: "ghi"},
+			},
+			want: []row{{"Food", 20, "ghi", "ghi"}, {"Home", 20, "ghi/2", "ghi"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := models.ImportBatch{FileName: tt.name}
+			summary, err := CommitImport(context.Background(), &batch, slices.Values(tt.rows), len(tt.rows), false, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summary.Imported != len(tt.want) {
+				t.Errorf("imported %d rows, want %d", summary.Imported, len(tt.want))
+			}
+
+			var txs []models.Transaction
+			if err := DB.Preload("Category").Where("batch_id = ?", batch.ID).Order("id").Find(&txs).Error; err != nil {
+				t.Fatal(err)
+			}
+			var got []row
+			for _, tx := range txs {
+				got = append(got, row{tx.Category.Name, tx.Amount, tx.ImportID, tx.SplitGroup})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("imported %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"peronal_finance_cli_manager/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// inbox selects the transactions waiting in the Uncategorized category
+func inbox(db *gorm.DB) *gorm.DB {
+	return db.Where("category_id IN (?)",
+		DB.Model(&models.Category{}).Select("id").Where("name = ?", UncategorizedCategory))
+}
+
+// GetInboxTransactions returns the transactions no rule could categorize,
+// oldest first
+func GetInboxTransactions() ([]models.Transaction, error) {
+	var txs []models.Transaction
+	err := DB.Scopes(inbox).
+		Preload("Category").
+		Order("date, id").
+		Find(&txs).Error
+	return txs, err
+}
+
+// CountInbox returns how many transactions wait to be categorized
+func CountInbox() (int64, error) {
+	var count int64
+	err := DB.Model(&models.Transaction{}).Scopes(inbox).Count(&count).Error
+	return count, err
+}
+
+// AssignCategory moves a transaction to an existing category
+func AssignCategory(txID uint, categoryName string) error {
+	var cat models.Category
+	if err := DB.Where("name = ?", categoryName).First(&cat).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("category '%s' not found", categoryName)
+		}
+		return err
+	}
+	var tx models.Transaction
+	if err := DB.Preload("Category").First(&tx, txID).Error; err != nil {
+		return err
+	}
+	if err := DB.Model(&models.Transaction{}).
+		Where("id = ?", txID).
+		Update("category_id", cat.ID).Error; err != nil {
+		return err
+	}
+	forgetCategory(tx, tx.Category.Name)
+	learnCategory(tx, cat.Name)
+	if err := resetBudgetAlerts(DB, tx.CategoryID, tx.Date); err != nil {
+		return err
+	}
+	// the spending that arrived may take the category over budget
+	_ = CheckBudget(DB, cat, tx.Amount, tx.Date.Format("2006-01-02"))
+	return nil
+}
+
+// TriageInbox runs the rules again over the inbox, e.g. after a rule was
+// added, and returns how many transactions they changed
+func TriageInbox() (int, error) {
+	defer invalidateClassifier()
+	return reapplyRules(inbox)
+}
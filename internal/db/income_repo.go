@@ -0,0 +1,159 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// incomeIn sums the transactions of the income categories within a
+// period, leaving transfers out
+func incomeIn(db *gorm.DB, period budget.Period) (float32, error) {
+	var total float32
+	err := db.Model(&models.Transaction{}).
+		Joins("JOIN categories c ON c.id = transactions.category_id").
+		Where("c.kind = ?", models.KindIncome).
+		Where("transactions.is_transfer = ?", false).
+		Where("date(transactions.date) >= ? AND date(transactions.date) < ?",
+			period.Start.Format("2006-01-02"), period.End.Format("2006-01-02")).
+		Select("COALESCE(SUM(transactions.amount), 0)").
+		Row().Scan(&total)
+	return total, err
+}
+
+// expectedIncome returns the monthly income set as expected, 0 when none is
+func expectedIncome(db *gorm.DB) (float32, error) {
+	value, err := getSetting(db, SettingExpectedIncome, "")
+	if err != nil || value == "" {
+		return 0, err
+	}
+	amount, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expected income '%s'", value)
+	}
+	return float32(amount), nil
+}
+
+// periodIncome is the income budgets are shares of for a period: the
+// expected income scaled to the period when one is set, otherwise the
+// income actually received in it
+func periodIncome(db *gorm.DB, period budget.Period) (float32, error) {
+	expected, err := expectedIncome(db)
+	if err != nil {
+		return 0, err
+	}
+	if expected > 0 {
+		return budget.ExpectedIncome(period, expected), nil
+	}
+	return incomeIn(db, period)
+}
+
+// GetExpectedIncome returns the monthly income set as expected, 0 when
+// budgets follow the income received
+func GetExpectedIncome() (float32, error) {
+	return expectedIncome(DB)
+}
+
+// SetExpectedIncome sets the monthly income that shares of income are
+// taken from; 0 goes back to the income actually received
+func SetExpectedIncome(amount float32) error {
+	if amount < 0 {
+		return errors.New("the expected income cannot be negative")
+	}
+	value := ""
+	if amount > 0 {
+		value = strconv.FormatFloat(float64(amount), 'f', 2, 32)
+	}
+	return setSetting(DB, SettingExpectedIncome, value)
+}
+
+// GetBudgetTemplate returns the budget template in use, the zero Template
+// when there is none
+func GetBudgetTemplate() (budget.Template, error) {
+	name, err := getSetting(DB, SettingBudgetTemplate, "")
+	if err != nil {
+		return budget.Template{}, err
+	}
+	return budget.FindTemplate(name)
+}
+
+// SetBudgetTemplate picks the budget template the groups are compared
+// with; empty means none
+func SetBudgetTemplate(name string) error {
+	if _, err := budget.FindTemplate(name); err != nil {
+		return err
+	}
+	return setSetting(DB, SettingBudgetTemplate, name)
+}
+
+// SetCategoryShare sets the budget of a category to a share of income, in
+// percent, 0 going back to its amount, and the template group it counts
+// towards
+func SetCategoryShare(id uint, percent float32, group string) error {
+	if err := budget.CheckPercent(percent); err != nil {
+		return err
+	}
+	group = strings.ToLower(strings.TrimSpace(group))
+
+	return DB.Transaction(func(db *gorm.DB) error {
+		var c models.Category
+		if err := db.First(&c, id).Error; err != nil {
+			return err
+		}
+		if percent > 0 && c.Kind == models.KindIncome {
+			return fmt.Errorf("'%s' is an income category, it cannot be a share of income", c.Name)
+		}
+		if c.BudgetPercent == percent && c.BudgetGroup == group {
+			return nil
+		}
+		if err := db.Model(&models.Category{}).Where("id = ?", id).
+			Updates(map[string]any{"budget_percent": percent, "budget_group": group}).Error; err != nil {
+			return err
+		}
+		return resetBudgetAlerts(db, id, time.Now())
+	})
+}
+
+// GetGroupStats compares the spending of every group of the budget
+// template with its share of income, for the calendar month offset months
+// from the one holding today. Without a template there are none.
+func GetGroupStats(today time.Time, offset int) ([]models.GroupStats, error) {
+	template, err := GetBudgetTemplate()
+	if err != nil || len(template.Shares) == 0 {
+		return nil, err
+	}
+	month := budget.Containing(budget.Monthly, time.Time{}, today).Shift(offset)
+
+	income, err := periodIncome(DB, month)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]models.GroupStats, 0, len(template.Shares))
+	for _, share := range template.Shares {
+		g := models.GroupStats{Group: share.Group, Share: share.Percent, Minimum: share.Minimum, Income: income}
+		for _, c := range categories {
+			if c.Kind == models.KindIncome || c.BudgetGroup != share.Group {
+				continue
+			}
+			spent, err := spentIn(DB, c.ID, month)
+			if err != nil {
+				return nil, err
+			}
+			g.Spent += spent
+			g.Categories = append(g.Categories, c.Name)
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
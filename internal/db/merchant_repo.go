@@ -0,0 +1,274 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"peronal_finance_cli_manager/internal/merchant"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// merchantCache holds the alias matcher of every merchant. It is built on
+// first use and dropped whenever a merchant changes.
+var merchantCache struct {
+	sync.Mutex
+	matcher *merchant.Matcher
+}
+
+func invalidateMerchants() {
+	merchantCache.Lock()
+	defer merchantCache.Unlock()
+	merchantCache.matcher = nil
+}
+
+// merchantMatcher returns the alias matcher of the stored merchants
+func merchantMatcher() (*merchant.Matcher, error) {
+	merchantCache.Lock()
+	defer merchantCache.Unlock()
+	if merchantCache.matcher != nil {
+		return merchantCache.matcher, nil
+	}
+
+	var merchants []models.Merchant
+	if err := DB.Preload("Category").Preload("Aliases").Order("id").Find(&merchants).Error; err != nil {
+		return nil, err
+	}
+	matcher, err := merchant.New(merchants)
+	if err != nil {
+		return nil, err
+	}
+	merchantCache.matcher = matcher
+	return matcher, nil
+}
+
+// MatchMerchant returns the merchant of a raw payee or description, nil
+// when no alias matches
+func MatchMerchant(text string) (*models.Merchant, error) {
+	matcher, err := merchantMatcher()
+	if err != nil {
+		return nil, err
+	}
+	m, _ := matcher.Match(text)
+	return m, nil
+}
+
+// merchantCategory is the default category of a merchant, empty when it
+// has none
+func merchantCategory(m *models.Merchant) string {
+	if m == nil || m.Category == nil {
+		return ""
+	}
+	return m.Category.Name
+}
+
+// GetMerchants returns every merchant with its aliases, by name
+func GetMerchants() ([]models.Merchant, error) {
+	var merchants []models.Merchant
+	err := DB.Preload("Category").Preload("Aliases").Order("name").Find(&merchants).Error
+	return merchants, err
+}
+
+// SaveMerchant creates the merchant, or updates it when it has an ID, and
+// replaces its aliases with patterns. categoryName may be empty.
+func SaveMerchant(m *models.Merchant, categoryName string, patterns []string) error {
+	defer invalidateMerchants()
+	return DB.Transaction(func(db *gorm.DB) error {
+		return saveMerchant(db, m, categoryName, patterns)
+	})
+}
+
+func saveMerchant(db *gorm.DB, m *models.Merchant, categoryName string, patterns []string) error {
+	m.Name = strings.TrimSpace(m.Name)
+	if m.Name == "" {
+		return errors.New("merchant name cannot be empty")
+	}
+	m.CategoryID, m.Category = nil, nil
+	if categoryName != "" {
+		var cat models.Category
+		if err := db.Where("name = ?", categoryName).First(&cat).Error; err != nil {
+			return fmt.Errorf("category '%s' not found", categoryName)
+		}
+		m.CategoryID, m.Category = &cat.ID, &cat
+	}
+
+	var aliases []models.MerchantAlias
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := merchant.Compile(p); err != nil {
+			return err
+		}
+		aliases = append(aliases, models.MerchantAlias{Pattern: p})
+	}
+
+	if err := db.Omit("Category", "Aliases").Save(m).Error; err != nil {
+		return err
+	}
+	if err := db.Where("merchant_id = ?", m.ID).Delete(&models.MerchantAlias{}).Error; err != nil {
+		return err
+	}
+	for i := range aliases {
+		aliases[i].MerchantID = m.ID
+	}
+	if len(aliases) > 0 {
+		if err := db.Create(&aliases).Error; err != nil {
+			return err
+		}
+	}
+	m.Aliases = aliases
+	return nil
+}
+
+// DeleteMerchant removes a merchant and its aliases. Transactions keep
+// the payee they were given.
+func DeleteMerchant(id uint) error {
+	defer invalidateMerchants()
+	return DB.Transaction(func(db *gorm.DB) error {
+		if err := db.Where("merchant_id = ?", id).Delete(&models.MerchantAlias{}).Error; err != nil {
+			return err
+		}
+		return db.Delete(&models.Merchant{}, id).Error
+	})
+}
+
+// GetPayees returns the distinct payees of the stored transactions
+func GetPayees() ([]models.PayeeCount, error) {
+	var payees []models.PayeeCount
+	err := DB.Model(&models.Transaction{}).
+		Select("payee, COUNT(*) AS count").
+		Where("payee <> ''").
+		Group("payee").
+		Order("payee").
+		Scan(&payees).Error
+	return payees, err
+}
+
+// MergePayees makes the given payees one merchant: the merchant is created
+// when needed, gets an alias for every payee so future imports and entries
+// are normalized, and the stored transactions are renamed. The raw payee
+// is kept as description where there is none. categoryName, when set,
+// becomes the merchant's default category. It returns how many
+// transactions were renamed.
+func MergePayees(payees []string, merchantName, categoryName string) (int, error) {
+	merchantName = strings.TrimSpace(merchantName)
+	if merchantName == "" {
+		return 0, errors.New("merchant name cannot be empty")
+	}
+	if len(payees) == 0 {
+		return 0, errors.New("no payee selected")
+	}
+
+	defer invalidateMerchants()
+	renamed := 0
+	err := DB.Transaction(func(db *gorm.DB) error {
+		var m models.Merchant
+		err := db.Preload("Category").Preload("Aliases").Where("name = ?", merchantName).First(&m).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			m = models.Merchant{Name: merchantName}
+		case err != nil:
+			return err
+		}
+		if categoryName == "" {
+			categoryName = merchantCategory(&m)
+		}
+
+		patterns := make([]string, 0, len(m.Aliases)+len(payees))
+		known := make(map[string]bool)
+		for _, a := range m.Aliases {
+			patterns = append(patterns, a.Pattern)
+			known[a.Pattern] = true
+		}
+		for _, p := range payees {
+			if pattern := merchant.AliasFor(p); !known[pattern] && p != merchantName {
+				patterns = append(patterns, pattern)
+				known[pattern] = true
+			}
+		}
+		if err := saveMerchant(db, &m, categoryName, patterns); err != nil {
+			return err
+		}
+
+		var txs []models.Transaction
+		if err := db.Where("payee IN ? AND payee <> ?", payees, merchantName).Find(&txs).Error; err != nil {
+			return err
+		}
+		changes, err := renamePayees(db, txs, func(models.Transaction) string { return merchantName })
+		if err != nil {
+			return err
+		}
+		renamed = len(changes)
+		return audit(db, AuditMergePayees, fmt.Sprintf("%d payee(s) merged into %s", len(payees), merchantName), changes)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if renamed > 0 {
+		invalidateClassifier()
+	}
+	return renamed, nil
+}
+
+// NormalizePayees renames the stored transactions whose payee or
+// description matches a merchant alias, e.g. after aliases were added.
+// It returns how many transactions were renamed.
+func NormalizePayees() (int, error) {
+	matcher, err := merchantMatcher()
+	if err != nil {
+		return 0, err
+	}
+
+	renamed := 0
+	err = DB.Transaction(func(db *gorm.DB) error {
+		var txs []models.Transaction
+		if err := db.Find(&txs).Error; err != nil {
+			return err
+		}
+		changes, err := renamePayees(db, txs, func(tx models.Transaction) string {
+			if m, ok := matcher.Match(tx.Payee, tx.Description); ok {
+				return m.Name
+			}
+			return tx.Payee
+		})
+		if err != nil {
+			return err
+		}
+		renamed = len(changes)
+		return audit(db, AuditMergePayees, fmt.Sprintf("%d payee(s) normalized", renamed), changes)
+	})
+	if renamed > 0 {
+		invalidateClassifier()
+	}
+	return renamed, err
+}
+
+// renamePayees gives every transaction the payee name returns, keeping the
+// raw payee as description where there is none
+func renamePayees(db *gorm.DB, txs []models.Transaction, name func(models.Transaction) string) ([]models.AuditChange, error) {
+	var changes []models.AuditChange
+	for _, tx := range txs {
+		payee := name(tx)
+		if payee == tx.Payee {
+			continue
+		}
+		updates := map[string]any{"payee": payee}
+		if tx.Description == "" {
+			updates["description"] = tx.Payee
+		}
+		if err := db.Model(&models.Transaction{}).Where("id = ?", tx.ID).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+		changes = append(changes, models.AuditChange{
+			TransactionID: tx.ID,
+			Field:         "payee",
+			From:          tx.Payee,
+			To:            payee,
+		})
+	}
+	return changes, nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestMergePayees(t *testing.T) {
+	openTestDB(t)
+
+	food := models.Category{Name: "Food"}
+	if err := DB.Create(&food).Error; err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	txs := []models.Transaction{
+		{CategoryID: food.ID, Amount: 4.5, Date: day, Payee: "STARBUCKS 123"},
+		{CategoryID: food.ID, Amount: 6, Date: day, Payee: "Starbucks Coffee"},
+		{CategoryID: food.ID, Amount: 12, Date: day, Payee: "Bakery"},
+	}
+	if err := DB.Omit("Category").Create(&txs).Error; err != nil {
+		t.Fatal(err)
+	}
+	payees := []string{"STARBUCKS 123", "Starbucks Coffee"}
+
+	// a rename that fails must not leave the merchant behind
+	if err := DB.Migrator().DropTable(&models.AuditEntry{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergePayees(payees, "Starbucks", "Food"); err == nil {
+		t.Fatal("MergePayees without an audit log succeeded")
+	}
+	if merchants, err := GetMerchants(); err != nil || len(merchants) != 0 {
+		t.Fatalf("merchants after a failed merge = %v, %v, want none", merchants, err)
+	}
+
+	if err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := MergePayees(payees, "Starbucks", "Food")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed != 2 {
+		t.Errorf("renamed %d transactions, want 2", renamed)
+	}
+	merchants, err := GetMerchants()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merchants) != 1 || len(merchants[0].Aliases) != 2 || merchantCategory(&merchants[0]) != "Food" {
+		t.Fatalf("merchants = %+v, want Starbucks in Food with 2 aliases", merchants)
+	}
+	// the merchant is matched right away
+	if m, err := MatchMerchant("STARBUCKS  123"); err != nil || m == nil || m.Name != "Starbucks" {
+		t.Errorf("MatchMerchant = %v, %v, want Starbucks", m, err)
+	}
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"peronal_finance_cli_manager/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecategorizeScope selects the transactions to check against the rules.
+// Zero dates leave that side of the range open; CategoryName, when set,
+// only checks the transactions of that category.
+type RecategorizeScope struct {
+	From         time.Time
+	To           time.Time
+	CategoryName string
+}
+
+// PlanRecategorization evaluates the current rules against the stored
+// transactions of scope and returns those a rule would move to another
+// category. Nothing is written. Split parts are left alone, and so are
+// moves to a category that no longer exists.
+func PlanRecategorization(scope RecategorizeScope) ([]models.CategoryMove, error) {
+	engine, err := RuleEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	query := DB.Preload("Category").Where("split_group = ?", "")
+	if !scope.From.IsZero() {
+		query = query.Where("date(date) >= ?", scope.From.Format("2006-01-02"))
+	}
+	if !scope.To.IsZero() {
+		query = query.Where("date(date) <= ?", scope.To.Format("2006-01-02"))
+	}
+	if scope.CategoryName != "" {
+		cat, err := GetCategoryByName(scope.CategoryName)
+		if err != nil {
+			return nil, fmt.Errorf("category '%s' not found", scope.CategoryName)
+		}
+		query = query.Where("category_id = ?", cat.ID)
+	}
+
+	var txs []models.Transaction
+	if err := query.Order("date, id").Find(&txs).Error; err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string]*models.Category)
+	var moves []models.CategoryMove
+	for _, tx := range txs {
+		name := engine.Apply(tx).Transaction.Category.Name
+		if name == "" || name == tx.Category.Name {
+			continue
+		}
+
+		to, ok := categories[name]
+		if !ok {
+			var cat models.Category
+			err := DB.Where("name = ?", name).First(&cat).Error
+			switch {
+			case err == nil:
+				to = &cat
+			case !errors.Is(err, gorm.ErrRecordNotFound):
+				return nil, err
+			}
+			categories[name] = to
+		}
+		if to == nil {
+			continue
+		}
+
+		moves = append(moves, models.CategoryMove{Transaction: tx, From: tx.Category, To: *to})
+	}
+	return moves, nil
+}
+
+// ApplyRecategorization moves the transactions in one database transaction
+// and records the moves in the audit log. A transaction whose category
+// changed since the plan was made is left where it is. It returns how many
+// transactions moved.
+func ApplyRecategorization(moves []models.CategoryMove) (int, error) {
+	var changes []models.AuditChange
+	checks := make(budgetChecks)
+	err := DB.Transaction(func(db *gorm.DB) error {
+		left := make(map[uint][]time.Time)
+		for _, move := range moves {
+			res := db.Model(&models.Transaction{}).
+				Where("id = ? AND category_id = ?", move.Transaction.ID, move.From.ID).
+				Update("category_id", move.To.ID)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			left[move.From.ID] = append(left[move.From.ID], move.Transaction.Date)
+			moved := move.Transaction
+			moved.CategoryID, moved.Category = move.To.ID, move.To
+			checks.add(moved)
+			changes = append(changes, models.AuditChange{
+				TransactionID: move.Transaction.ID,
+				Field:         "category",
+				From:          move.From.Name,
+				To:            move.To.Name,
+			})
+		}
+		if err := resetBudgetAlertsOf(db, left); err != nil {
+			return err
+		}
+		summary := fmt.Sprintf("%d transaction(s) re-categorized by the rules", len(changes))
+		return audit(db, AuditRecategorize, summary, changes)
+	})
+	if err != nil {
+		return 0, err
+	}
+	moved := make(map[uint]bool, len(changes))
+	for _, c := range changes {
+		moved[c.TransactionID] = true
+	}
+	for _, move := range moves {
+		if moved[move.Transaction.ID] {
+			forgetCategory(move.Transaction, move.From.Name)
+			learnCategory(move.Transaction, move.To.Name)
+		}
+	}
+	// the spending that arrived may take the new categories over budget
+	checks.run(DB)
+	return len(changes), nil
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"errors"
+	"peronal_finance_cli_manager/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Setting keys
+const (
+	SettingBudgetMode     = "budget_mode"
+	SettingBudgetTemplate = "budget_template"
+	SettingExpectedIncome = "expected_income"
+)
+
+// getSetting returns the value stored for key, or fallback when none is
+func getSetting(db *gorm.DB, key, fallback string) (string, error) {
+	var s models.Setting
+	err := db.Where("key = ?", key).First(&s).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fallback, nil
+	}
+	if err != nil {
+		return fallback, err
+	}
+	return s.Value, nil
+}
+
+// setSetting stores the value of key, replacing the one it had
+func setSetting(db *gorm.DB, key, value string) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value"}),
+	}).Create(&models.Setting{Key: key, Value: value}).Error
+}
+
+// GetSettings returns every stored setting
+func GetSettings() ([]models.Setting, error) {
+	var settings []models.Setting
+	err := DB.Order("key").Find(&settings).Error
+	return settings, err
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"peronal_finance_cli_manager/internal/models"
-	"peronal_finance_cli_manager/internal/transaction"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,40 +11,105 @@ import (
 
 var _ *gorm.DB
 
-func CreateTransaction(categoryName string, amount float32, dateStr string) (*models.Transaction, error) {
-	var cat models.Category
-	if err := DB.Where("name = ?", categoryName).First(&cat).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("category '%s' not found", categoryName)
-		}
-		return nil, err
-	}
-
+// CreateTransaction adds a transaction entered by hand. The description is
+// normalized to its merchant's name as payee, then the rules run on it:
+// they may tag it, rename it, mark it as a transfer or split it. The
+// category typed in wins over the merchant's default category, which wins
+// over the rules; it may be left empty when either assigns one. The parts
+// of a split are written in one database transaction and the first one is
+// returned; budgets are checked once all of them are in.
+func CreateTransaction(categoryName, description string, amount float32, dateStr string) (*models.Transaction, error) {
 	// parse date string
 	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid date format, use YYYY-MM-DD")
 	}
 
-	tx := &models.Transaction{
-		CategoryID: cat.ID,
-		Amount:     amount,
-		Date:       date,
+	tx := models.Transaction{
+		Amount:      amount,
+		Date:        date,
+		Payee:       description,
+		Description: description,
+	}
+	merchants, err := merchantMatcher()
+	if err != nil {
+		return nil, err
+	}
+	m, _ := merchants.Normalize(&tx)
+	if categoryName == "" {
+		categoryName = merchantCategory(m)
 	}
 
-	if err := DB.Create(tx).Error; err != nil {
+	res, err := ApplyRules(tx)
+	if err != nil {
 		return nil, err
 	}
 
-	// attach category for convenience
-	tx.Category = cat
+	parts := res.Splits
+	if categoryName != "" || parts == nil {
+		tx := res.Transaction
+		if categoryName != "" {
+			tx.Category.Name = categoryName
+		}
+		parts = []models.Transaction{tx}
+	} else {
+		group := fmt.Sprintf("manual-%d", time.Now().UnixNano())
+		for i := range parts {
+			parts[i].SplitGroup = group
+		}
+	}
+
+	// look every category up before inserting anything
+	categories := make([]models.Category, len(parts))
+	for i, tx := range parts {
+		if tx.Category.Name == "" {
+			return nil, fmt.Errorf("category cannot be empty, no rule matches")
+		}
+		if err := DB.Where("name = ?", tx.Category.Name).First(&categories[i]).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("category '%s' not found", tx.Category.Name)
+			}
+			return nil, err
+		}
+	}
+
+	// the parts of a split are written together or not at all
+	created := make([]models.Transaction, len(parts))
+	err = DB.Transaction(func(db *gorm.DB) error {
+		for i, tx := range parts {
+			tx.CategoryID = categories[i].ID
+			tx.Category = models.Category{}
+			if err := db.Create(&tx).Error; err != nil {
+				return err
+			}
+			// attach category for convenience
+			tx.Category = categories[i]
+			created[i] = tx
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Check budget
-	if err := CheckBudget(DB, cat, amount, dateStr); err != nil {
-		fmt.Println("Budget alert triggered")
+	// check every category once, with the parts booked to it
+	var touched []models.Category
+	amounts := make(map[uint]float32, len(created))
+	for _, tx := range created {
+		learnCategory(tx, tx.Category.Name)
+		if _, ok := amounts[tx.CategoryID]; !ok {
+			touched = append(touched, tx.Category)
+		}
+		amounts[tx.CategoryID] += tx.Amount
+	}
+	// an alert that could not be sent is tried again with the next
+	// transaction of the category, as its thresholds are not recorded
+	for _, cat := range touched {
+		_ = CheckBudget(DB, cat, amounts[cat.ID], date.Format("2006-01-02"))
 	}
+	_ = CheckGoals()
 
-	return tx, nil
+	return &created[0], nil
 }
 
 func GetTransactionsByCategory(categoryID uint) ([]models.Transaction, error) {
@@ -60,33 +124,29 @@ func GetTransactionsByCategory(categoryID uint) ([]models.Transaction, error) {
 	return txs, err
 }
 
-// ImportTransactionsFromFile parses a file (CSV/OFX) and inserts transactions into the DB.
-func ImportTransactionsFromFile(filePath string) ([]models.Transaction, error) {
-	var transactions []models.Transaction
-	var err error
-
-	switch transaction.DetectFormat(filePath) {
-	case "csv":
-		transactions, err = transaction.ParseCSV(filePath)
-		if err != nil {
-			return nil, err
-		}
-	default:
-		return nil, errors.New("unsupported file format")
+// GetTransaction returns one transaction with its category
+func GetTransaction(id uint) (*models.Transaction, error) {
+	var tx models.Transaction
+	if err := DB.Preload("Category").First(&tx, id).Error; err != nil {
+		return nil, err
 	}
+	return &tx, nil
+}
 
-	var imported []models.Transaction
-	for _, tx := range transactions {
-		newTx, err := CreateTransaction(tx.Category.Name, tx.Amount, tx.Date.Format("2006-01-02"))
-		if err != nil {
-			// Skip invalid transactions but log error
-			fmt.Printf("Failed to import transaction: %v\n", err)
-			continue
-		}
-		imported = append(imported, *newTx)
+func DeleteTransaction(id uint) error {
+	var tx models.Transaction
+	if err := DB.Preload("Category").First(&tx, id).Error; err != nil {
+		return err
 	}
-
-	return imported, nil
+	if err := DB.Delete(&models.Transaction{}, id).Error; err != nil {
+		return err
+	}
+	forgetCategory(tx, tx.Category.Name)
+	if err := resetBudgetAlerts(DB, tx.CategoryID, tx.Date); err != nil {
+		return err
+	}
+	_ = CheckGoals()
+	return nil
 }
 
 func GetAllTransactions() ([]models.Transaction, error) {
@@ -106,7 +166,7 @@ func GetMonthlyExpenses(monthStr string) (map[string]float32, error) {
 		SELECT c.name, SUM(t.amount) as total
 		FROM transactions t
 		JOIN categories c ON t.category_id = c.id
-		WHERE strftime('%Y-%m', t.date) = ?
+		WHERE strftime('%Y-%m', t.date) = ? AND NOT t.is_transfer
 		GROUP BY c.name
 	`, monthStr).Rows()
 	if err != nil {
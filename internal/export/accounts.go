@@ -0,0 +1,83 @@
+package export
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+)
+
+// Account roots categories and bank accounts are written under in journals
+const (
+	ExpensesRoot = "Expenses"
+	IncomeRoot   = "Income"
+	AssetsRoot   = "Assets"
+)
+
+// UnassignedAccount stands in for transactions without an account
+const UnassignedAccount = AssetsRoot + ":Unassigned"
+
+// balanceRoots are account roots that are kept as they are when an account
+// name already starts with one, e.g. a credit card under Liabilities
+var balanceRoots = []string{AssetsRoot, "Liabilities", "Equity"}
+
+// CategoryAccount returns the journal account of a category. Income
+// categories go under the Income root, the Income category being the root
+// itself; every other category is an expense.
+func CategoryAccount(c models.Category) string {
+	switch {
+	case c.Kind != models.KindIncome:
+		return ExpensesRoot + ":" + accountName(c.Name)
+	case c.Name == models.IncomeCategory:
+		return IncomeRoot
+	}
+	return IncomeRoot + ":" + accountName(c.Name)
+}
+
+// CategoryFromAccount returns the category of an Expenses or Income
+// account and whether the account is one
+func CategoryFromAccount(account string) (string, bool) {
+	switch {
+	case account == IncomeRoot:
+		return models.IncomeCategory, true
+	case strings.HasPrefix(account, IncomeRoot+":"):
+		return strings.TrimPrefix(account, IncomeRoot+":"), true
+	case strings.HasPrefix(account, ExpensesRoot+":"):
+		return strings.TrimPrefix(account, ExpensesRoot+":"), true
+	}
+	return "", false
+}
+
+// AssetAccount returns the journal account of a transaction's bank account
+func AssetAccount(account string) string {
+	if account == "" {
+		return UnassignedAccount
+	}
+	for _, root := range balanceRoots {
+		if strings.HasPrefix(account, root+":") {
+			return accountName(account)
+		}
+	}
+	return AssetsRoot + ":" + accountName(account)
+}
+
+// AccountFromAsset reverses AssetAccount
+func AccountFromAsset(account string) string {
+	switch {
+	case account == UnassignedAccount:
+		return ""
+	case strings.HasPrefix(account, AssetsRoot+":"):
+		return strings.TrimPrefix(account, AssetsRoot+":")
+	}
+	return account
+}
+
+// accountName makes a name usable as ledger account: two spaces or a tab
+// would end the account name, so whitespace runs become a single space.
+func accountName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// isIncome reports whether the amount of a category is booked as income,
+// which journals write with the opposite sign
+func isIncome(account string) bool {
+	return account == IncomeRoot || strings.HasPrefix(account, IncomeRoot+":")
+}
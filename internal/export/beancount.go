@@ -0,0 +1,106 @@
+package export
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"peronal_finance_cli_manager/internal/models"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CategoryKey is the metadata key holding a category name that had to be
+// changed to become a valid Beancount account
+const CategoryKey = "category"
+
+// WriteBeancount writes categories and bank accounts as open directives and
+// transactions as Beancount entries. Account names are reduced to what
+// Beancount accepts; the original category name is kept as metadata when
+// that changed it. Transactions keep their fields as metadata too, the
+// transfer mark as a boolean, and amounts carry their own currency.
+func WriteBeancount(w io.Writer, categories []models.Category, txs []models.Transaction, opts Options) error {
+	currency := opts.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	out := bufio.NewWriter(w)
+	all := entries(categories, txs)
+	opened := openDate(all).Format("2006-01-02")
+
+	fmt.Fprintf(out, "option \"operating_currency\" %s\n\n", strconv.Quote(currency))
+
+	for _, c := range sortedCategories(categories) {
+		account := CategoryAccount(c)
+		fmt.Fprintf(out, "%s open %s\n", opened, beancountAccount(account))
+		if c.Budget != 0 {
+			fmt.Fprintf(out, "  %s: %.2f %s\n", BudgetKey, c.Budget, currency)
+		}
+		if beancountAccount(account) != account {
+			fmt.Fprintf(out, "  %s: %s\n", CategoryKey, strconv.Quote(c.Name))
+		}
+	}
+
+	assets := make(map[string]bool)
+	for _, e := range all {
+		account := e.asset().account
+		if assets[account] {
+			continue
+		}
+		assets[account] = true
+		fmt.Fprintf(out, "%s open %s\n", opened, beancountAccount(account))
+	}
+	fmt.Fprintln(out)
+
+	for _, e := range all {
+		fmt.Fprintf(out, "%s * %s \"\"\n", e.tx.Date.Format("2006-01-02"), strconv.Quote(e.tx.Payee))
+		for _, m := range e.metadata() {
+			value := strconv.Quote(m[1])
+			if m[0] == TransferKey {
+				value = "TRUE"
+			}
+			fmt.Fprintf(out, "  %s: %s\n", m[0], value)
+		}
+		amountCurrency := cmp.Or(e.tx.Currency, currency)
+		for _, p := range e.postings {
+			fmt.Fprintf(out, "  %-40s  %.2f %s\n", beancountAccount(p.account), p.amount, amountCurrency)
+		}
+		fmt.Fprintln(out)
+	}
+
+	return out.Flush()
+}
+
+// beancountAccount makes every component of an account start with a capital
+// letter or digit and hold only letters, digits and dashes. A bare root like
+// Income gets a component of the same name, Beancount needs at least one.
+func beancountAccount(account string) string {
+	parts := strings.Split(account, ":")
+	if len(parts) == 1 {
+		parts = append(parts, parts[0])
+	}
+	for i, part := range parts {
+		var b strings.Builder
+		dash := false
+		for _, r := range part {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(r)
+				dash = false
+			} else if !dash && b.Len() > 0 {
+				b.WriteRune('-')
+				dash = true
+			}
+		}
+
+		name := strings.TrimSuffix(b.String(), "-")
+		if name == "" {
+			name = "X"
+		}
+		runes := []rune(name)
+		runes[0] = unicode.ToUpper(runes[0])
+		parts[i] = string(runes)
+	}
+	return strings.Join(parts, ":")
+}
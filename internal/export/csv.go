@@ -0,0 +1,82 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+)
+
+// csvColumns are the CSV columns that can be exported. The names match the
+// headers the CSV importer reads, so an export with DefaultColumns can be
+// imported again.
+var csvColumns = map[string]func(tx models.Transaction, category string) string{
+	"id":          func(tx models.Transaction, _ string) string { return fmt.Sprint(tx.ID) },
+	"category":    func(_ models.Transaction, category string) string { return category },
+	"amount":      func(tx models.Transaction, _ string) string { return fmt.Sprintf("%.2f", tx.Amount) },
+	"date":        func(tx models.Transaction, _ string) string { return tx.Date.Format("2006-01-02") },
+	"payee":       func(tx models.Transaction, _ string) string { return tx.Payee },
+	"description": func(tx models.Transaction, _ string) string { return tx.Description },
+	"account":     func(tx models.Transaction, _ string) string { return tx.Account },
+	"currency":    func(tx models.Transaction, _ string) string { return tx.Currency },
+	"tags":        func(tx models.Transaction, _ string) string { return tx.Tags },
+	"transfer":    func(tx models.Transaction, _ string) string { return fmt.Sprint(tx.IsTransfer) },
+	"reference":   func(tx models.Transaction, _ string) string { return tx.ImportID },
+}
+
+// DefaultColumns is the app's own CSV layout
+var DefaultColumns = []string{"category", "amount", "date", "payee", "account", "reference"}
+
+// ParseColumns reads a comma separated column list and checks every name
+func ParseColumns(value string) ([]string, error) {
+	var columns []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := csvColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column '%s'", name)
+		}
+		columns = append(columns, name)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns given")
+	}
+	return columns, nil
+}
+
+// WriteCSV writes a header and one row per transaction with the columns
+// of opts, or DefaultColumns
+func WriteCSV(w io.Writer, categories []models.Category, txs []models.Transaction, opts Options) error {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+	for _, name := range columns {
+		if _, ok := csvColumns[name]; !ok {
+			return fmt.Errorf("unknown column '%s'", name)
+		}
+	}
+
+	names := categoryNames(categories)
+	out := csv.NewWriter(w)
+	if err := out.Write(columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for _, tx := range txs {
+		category := categoryName(names, tx)
+		for i, name := range columns {
+			record[i] = csvColumns[name](tx, category)
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
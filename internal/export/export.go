@@ -0,0 +1,98 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"peronal_finance_cli_manager/internal/models"
+)
+
+// Writer writes transactions in one export format. categories resolve the
+// category names of transactions whose Category was not preloaded.
+type Writer func(w io.Writer, categories []models.Category, txs []models.Transaction, opts Options) error
+
+// formats are the export formats in the order they are offered
+var formats = []struct {
+	name  string
+	write Writer
+}{
+	{"csv", WriteCSV},
+	{"json", WriteJSON},
+	{"ndjson", WriteNDJSON},
+	{"ofx", WriteOFX},
+	{"ledger", WriteLedger},
+	{"beancount", WriteBeancount},
+}
+
+// Formats returns the names of the export formats
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// Lookup returns the writer of an export format
+func Lookup(format string) (Writer, error) {
+	for _, f := range formats {
+		if f.name == format {
+			return f.write, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported export format '%s'", format)
+}
+
+// categoryName returns the name of a transaction's category, falling back
+// to the preloaded category
+func categoryName(names map[uint]string, tx models.Transaction) string {
+	if name, ok := names[tx.CategoryID]; ok {
+		return name
+	}
+	return tx.Category.Name
+}
+
+// categoryOf returns a transaction's category, falling back to the
+// preloaded category
+func categoryOf(categories map[uint]models.Category, tx models.Transaction) models.Category {
+	if c, ok := categories[tx.CategoryID]; ok {
+		return c
+	}
+	return tx.Category
+}
+
+// categoriesByID maps category ids to categories
+func categoriesByID(categories []models.Category) map[uint]models.Category {
+	byID := make(map[uint]models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	return byID
+}
+
+// categoryNames maps category ids to names
+func categoryNames(categories []models.Category) map[uint]string {
+	names := make(map[uint]string, len(categories))
+	for _, c := range categories {
+		names[c.ID] = c.Name
+	}
+	return names
+}
+
+// WriteFile writes transactions to a new file in the given format
+func WriteFile(path, format string, categories []models.Category, txs []models.Transaction, opts Options) error {
+	write, err := Lookup(format)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file, categories, txs, opts); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
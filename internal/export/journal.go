@@ -0,0 +1,147 @@
+package export
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Options tune how transactions are written
+type Options struct {
+	// Currency is written after every amount of a transaction without a
+	// currency of its own. Beancount and OFX require one and fall back to
+	// DefaultCurrency; other amounts are bare.
+	Currency string
+	// Columns are the CSV columns, DefaultColumns when empty
+	Columns []string
+}
+
+// DefaultCurrency is used for Beancount and OFX exports without a currency
+const DefaultCurrency = "USD"
+
+// ImportIDKey is the metadata key holding a transaction's import identity,
+// so re-importing an exported journal skips what is already there
+const ImportIDKey = "import_id"
+
+// BudgetKey is the metadata key holding a category's budget
+const BudgetKey = "budget"
+
+// TagsKey is the metadata key holding a transaction's comma separated tags
+const TagsKey = "tags"
+
+// Metadata keys of the transaction fields a journal has no place for: the
+// raw description, the currency, the transfer mark and the split group
+const (
+	DescriptionKey = "desc"
+	CurrencyKey    = "currency"
+	TransferKey    = "transfer"
+	SplitKey       = "split"
+)
+
+// posting is one leg of a journal entry
+type posting struct {
+	account string
+	amount  float32
+}
+
+// entry is a transaction in double-entry form: the category accounts get
+// the amounts and the bank account, the last posting, balances them. The
+// parts of a split transaction share one entry with a posting per part;
+// tx is the first part.
+type entry struct {
+	tx       models.Transaction
+	postings []posting
+}
+
+// asset is the bank account posting of the entry
+func (e entry) asset() posting {
+	return e.postings[len(e.postings)-1]
+}
+
+func entries(categories []models.Category, txs []models.Transaction) []entry {
+	byID := categoriesByID(categories)
+
+	sorted := make([]models.Transaction, len(txs))
+	copy(sorted, txs)
+	// by id within a day, so the parts of a split keep their order
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Date.Equal(sorted[j].Date) {
+			return sorted[i].Date.Before(sorted[j].Date)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	// parts of a split booked on the same day and account go together
+	type splitKey struct {
+		group, account string
+		date           time.Time
+	}
+	splits := make(map[splitKey]int)
+
+	result := make([]entry, 0, len(sorted))
+	for _, tx := range sorted {
+		account := CategoryAccount(categoryOf(byID, tx))
+		amount := tx.Amount
+		if isIncome(account) {
+			amount = -amount
+		}
+		p := posting{account: account, amount: amount}
+
+		key := splitKey{group: tx.SplitGroup, account: tx.Account, date: tx.Date}
+		if i, ok := splits[key]; ok && tx.SplitGroup != "" {
+			e := &result[i]
+			asset := e.asset()
+			asset.amount -= amount
+			e.postings = append(e.postings[:len(e.postings)-1], p, asset)
+			continue
+		}
+		if tx.SplitGroup != "" {
+			splits[key] = len(result)
+		}
+		result = append(result, entry{
+			tx:       tx,
+			postings: []posting{p, {account: AssetAccount(tx.Account), amount: -amount}},
+		})
+	}
+	return result
+}
+
+// metadata returns the metadata of an entry's transaction, in the order
+// it is written, leaving out what is empty. Values are kept on one line.
+func (e entry) metadata() [][2]string {
+	tx := e.tx
+	var meta [][2]string
+	add := func(key, value string) {
+		if value != "" {
+			meta = append(meta, [2]string{key, strings.Join(strings.Fields(value), " ")})
+		}
+	}
+	add(ImportIDKey, tx.ImportID)
+	add(TagsKey, tx.Tags)
+	add(DescriptionKey, tx.Description)
+	add(CurrencyKey, tx.Currency)
+	if tx.IsTransfer {
+		add(TransferKey, "true")
+	}
+	add(SplitKey, tx.SplitGroup)
+	return meta
+}
+
+// openDate is the day accounts are opened on: the first transaction, or
+// today for an export without transactions
+func openDate(txs []entry) time.Time {
+	if len(txs) == 0 {
+		return time.Now()
+	}
+	return txs[0].tx.Date
+}
+
+func sortedCategories(categories []models.Category) []models.Category {
+	sorted := make([]models.Category, len(categories))
+	copy(sorted, categories)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
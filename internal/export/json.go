@@ -0,0 +1,68 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"peronal_finance_cli_manager/internal/models"
+)
+
+// jsonTransaction is the exported shape of a transaction
+type jsonTransaction struct {
+	ID          uint     `json:"id"`
+	Date        string   `json:"date"`
+	Amount      float32  `json:"amount"`
+	Category    string   `json:"category"`
+	Payee       string   `json:"payee,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Account     string   `json:"account,omitempty"`
+	Currency    string   `json:"currency,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Transfer    bool     `json:"transfer,omitempty"`
+	ImportID    string   `json:"import_id,omitempty"`
+	SplitGroup  string   `json:"split_group,omitempty"`
+}
+
+func toJSON(names map[uint]string, tx models.Transaction) jsonTransaction {
+	return jsonTransaction{
+		ID:          tx.ID,
+		Date:        tx.Date.Format("2006-01-02"),
+		Amount:      tx.Amount,
+		Category:    categoryName(names, tx),
+		Payee:       tx.Payee,
+		Description: tx.Description,
+		Account:     tx.Account,
+		Currency:    tx.Currency,
+		Tags:        models.SplitTags(tx.Tags),
+		Transfer:    tx.IsTransfer,
+		ImportID:    tx.ImportID,
+		SplitGroup:  tx.SplitGroup,
+	}
+}
+
+// WriteJSON writes the transactions as one JSON array
+func WriteJSON(w io.Writer, categories []models.Category, txs []models.Transaction, opts Options) error {
+	names := categoryNames(categories)
+	list := make([]jsonTransaction, 0, len(txs))
+	for _, tx := range txs {
+		list = append(list, toJSON(names, tx))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// WriteNDJSON writes one JSON object per line, so large exports can be
+// streamed into other tools
+func WriteNDJSON(w io.Writer, categories []models.Category, txs []models.Transaction, opts Options) error {
+	names := categoryNames(categories)
+	out := bufio.NewWriter(w)
+	enc := json.NewEncoder(out)
+	for _, tx := range txs {
+		if err := enc.Encode(toJSON(names, tx)); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"peronal_finance_cli_manager/internal/models"
+)
+
+// WriteLedger writes categories as account declarations and transactions
+// as journal entries in ledger/hledger syntax. The payee is the entry
+// description; the import identity, tags, raw description, currency,
+// transfer mark and split group are kept as tags, and the parts of a
+// split share one entry. Amounts carry the transaction's currency, or
+// opts.Currency.
+func WriteLedger(w io.Writer, categories []models.Category, txs []models.Transaction, opts Options) error {
+	out := bufio.NewWriter(w)
+
+	for _, c := range sortedCategories(categories) {
+		fmt.Fprintf(out, "account %s\n", CategoryAccount(c))
+		if c.Budget != 0 {
+			fmt.Fprintf(out, "    ; %s: %.2f\n", BudgetKey, c.Budget)
+		}
+	}
+	if len(categories) > 0 {
+		fmt.Fprintln(out)
+	}
+
+	for _, e := range entries(categories, txs) {
+		header := e.tx.Date.Format("2006-01-02") + " *"
+		if e.tx.Payee != "" {
+			header += " " + e.tx.Payee
+		}
+		fmt.Fprintln(out, header)
+		for _, m := range e.metadata() {
+			fmt.Fprintf(out, "    ; %s: %s\n", m[0], m[1])
+		}
+		currency := cmp.Or(e.tx.Currency, opts.Currency)
+		for _, p := range e.postings {
+			fmt.Fprintf(out, "    %-40s  %s\n", p.account, ledgerAmount(p.amount, currency))
+		}
+		fmt.Fprintln(out)
+	}
+
+	return out.Flush()
+}
+
+func ledgerAmount(amount float32, currency string) string {
+	if currency == "" {
+		return fmt.Sprintf("%.2f", amount)
+	}
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
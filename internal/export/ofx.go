@@ -0,0 +1,121 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"peronal_finance_cli_manager/internal/models"
+	"sort"
+	"time"
+
+	"github.com/aclindsa/ofxgo"
+)
+
+// WriteOFX writes an OFX 2.2 bank statement per account. Expenses become
+// debits and income credits, the same convention the OFX importer reads,
+// and the import identity is the FITID so re-importing skips every row.
+func WriteOFX(w io.Writer, categories []models.Category, txs []models.Transaction, opts Options) error {
+	currency := opts.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	curDef, err := ofxgo.NewCurrSymbol(currency)
+	if err != nil {
+		return err
+	}
+
+	byID := categoriesByID(categories)
+	now := ofxgo.Date{Time: time.Now()}
+
+	byAccount := make(map[string][]models.Transaction)
+	for _, tx := range txs {
+		byAccount[tx.Account] = append(byAccount[tx.Account], tx)
+	}
+	accounts := make([]string, 0, len(byAccount))
+	for account := range byAccount {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	resp := ofxgo.Response{
+		Version: ofxgo.OfxVersion220,
+		Signon: ofxgo.SignonResponse{
+			Status:   ofxgo.Status{Code: 0, Severity: "INFO"},
+			DtServer: now,
+			Language: "ENG",
+		},
+	}
+
+	for _, account := range accounts {
+		list := &ofxgo.TransactionList{DtStart: now, DtEnd: now}
+		var balance big.Rat
+
+		for i, tx := range byAccount[account] {
+			date := ofxgo.Date{Time: tx.Date}
+			if i == 0 || tx.Date.Before(list.DtStart.Time) {
+				list.DtStart = date
+			}
+			if i == 0 || tx.Date.After(list.DtEnd.Time) {
+				list.DtEnd = date
+			}
+
+			st := ofxgo.Transaction{
+				TrnType:  ofxgo.TrnTypeDebit,
+				DtPosted: date,
+				FiTID:    ofxgo.String(tx.ImportID),
+				Name:     ofxgo.String(tx.Payee),
+				Memo:     ofxgo.String(categoryOf(byID, tx).Name),
+			}
+			if st.FiTID == "" {
+				st.FiTID = ofxgo.String(fmt.Sprintf("tx-%d", tx.ID))
+			}
+			if st.Name == "" {
+				st.Name = st.Memo
+			}
+
+			amount := new(big.Rat).SetFloat64(float64(tx.Amount))
+			if categoryOf(byID, tx).Kind == models.KindIncome {
+				st.TrnType = ofxgo.TrnTypeCredit
+			} else {
+				amount.Neg(amount)
+			}
+			// amounts are stored with cents precision
+			amount.SetString(amount.FloatString(2))
+			st.TrnAmt.Rat = *amount
+			balance.Add(&balance, amount)
+
+			list.Transactions = append(list.Transactions, st)
+		}
+
+		uid, err := ofxgo.RandomUID()
+		if err != nil {
+			return err
+		}
+		acctID := account
+		if acctID == "" {
+			acctID = "unassigned"
+		}
+
+		stmt := &ofxgo.StatementResponse{
+			TrnUID: *uid,
+			Status: ofxgo.Status{Code: 0, Severity: "INFO"},
+			CurDef: *curDef,
+			BankAcctFrom: ofxgo.BankAcct{
+				BankID:   "0",
+				AcctID:   ofxgo.String(acctID),
+				AcctType: ofxgo.AcctTypeChecking,
+			},
+			BankTranList: list,
+			DtAsOf:       list.DtEnd,
+		}
+		stmt.BalAmt.Rat = balance
+		resp.Bank = append(resp.Bank, stmt)
+	}
+
+	buf, err := resp.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,285 @@
+// Package forecast projects the spending of a category to the end of its
+// budget period from the pace so far, the recurring payments still due and
+// the spending of the same period a year before.
+package forecast
+
+import (
+	"math"
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// recurringPeriods is how many periods in a row a payment must show up in
+// to count as recurring, and the history periods the pace is averaged over
+const recurringPeriods = 3
+
+// recurringSpread is how far the amounts of a recurring payment may stray
+// from their median, as a fraction of it
+const recurringSpread = 0.2
+
+// recurringGrace is how many days past its usual day a recurring payment
+// may still come in; later than that it is taken as skipped this period,
+// such as a cancelled subscription
+const recurringGrace = 7
+
+// Seasonality factors are kept within these bounds, so one odd period a
+// year ago does not swamp the forecast
+const (
+	minSeasonality = 0.5
+	maxSeasonality = 2.0
+)
+
+// Recurring is a payment that came back every period, such as rent or a
+// subscription
+type Recurring struct {
+	Payee  string
+	Amount float32
+	// Day is the usual day of the period it is paid on, 0 for the first
+	Day int
+}
+
+// Date is the day the payment falls due in period, the last day of a
+// period too short to hold its usual day
+func (r Recurring) Date(period budget.Period) time.Time {
+	return period.Start.AddDate(0, 0, min(r.Day, days(period.Start, period.End)-1))
+}
+
+// Projection is the spending expected by the end of a period
+type Projection struct {
+	Period budget.Period
+	// Spent so far in the period
+	Spent float32
+	// Pace is the spending expected for the rest of the period, besides
+	// the recurring payments, with Seasonality applied
+	Pace float32
+	// Due are the recurring payments not yet made this period and not
+	// more than recurringGrace days past their date, adding up to
+	// Recurring
+	Due       []Recurring
+	Recurring float32
+	// Seasonality compares the same period a year before with the year
+	// around it; 1 when there is no such history
+	Seasonality float64
+}
+
+// Projected is the spending expected by the end of the period
+func (p Projection) Projected() float32 {
+	return p.Spent + p.Pace + p.Recurring
+}
+
+// Project forecasts the spending of a category for the period holding
+// today. history holds the transactions of the category, from at least a
+// year before the period up to today; transfers are left out.
+func Project(period budget.Period, today time.Time, history []models.Transaction) Projection {
+	p := Projection{Period: period, Seasonality: 1}
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	var current []models.Transaction
+	for _, tx := range history {
+		if !tx.IsTransfer && period.Contains(tx.Date) && !tx.Date.After(today) {
+			current = append(current, tx)
+			p.Spent += tx.Amount
+		}
+	}
+
+	total := days(period.Start, period.End)
+	elapsed := days(period.Start, today) + 1
+	if !period.Contains(today) || elapsed >= total {
+		return p
+	}
+
+	// recurring payments are forecast on their own, so they are left out
+	// of the pace
+	recurring := FindRecurring(period, history)
+	skip := make(map[string]bool, len(recurring))
+	variable := p.Spent
+	for _, r := range recurring {
+		skip[r.Payee] = true
+		if paid, ok := paidIn(r, current); ok {
+			variable -= paid
+			continue
+		}
+		if today.After(r.Date(period).AddDate(0, 0, recurringGrace)) {
+			continue
+		}
+		p.Due = append(p.Due, r)
+		p.Recurring += r.Amount
+	}
+
+	// early in the period the pace so far says little: lean on the pace of
+	// the periods before until the current one takes over
+	pace := float64(variable) / float64(elapsed)
+	if past, ok := pastPace(period, history, skip); ok {
+		weight := float64(elapsed) / float64(total)
+		pace = weight*pace + (1-weight)*past
+	}
+
+	p.Seasonality = seasonality(period, history, skip)
+	p.Pace = float32(max(pace, 0) * float64(total-elapsed) * p.Seasonality)
+	return p
+}
+
+// FindRecurring returns the payments made in each of the periods right
+// before period, by the same payee and for about the same amount
+func FindRecurring(period budget.Period, history []models.Transaction) []Recurring {
+	type seen struct {
+		amounts []float64
+		days    []int
+		periods map[int]bool
+	}
+	payees := make(map[string]*seen)
+	var order []string
+
+	for i := 1; i <= recurringPeriods; i++ {
+		past := period.Shift(-i)
+		for _, tx := range history {
+			if tx.IsTransfer || !past.Contains(tx.Date) {
+				continue
+			}
+			key := payeeKey(tx)
+			if key == "" {
+				continue
+			}
+			s, ok := payees[key]
+			if !ok {
+				s = &seen{periods: make(map[int]bool)}
+				payees[key] = s
+				order = append(order, key)
+			}
+			s.amounts = append(s.amounts, float64(tx.Amount))
+			s.days = append(s.days, days(past.Start, tx.Date))
+			s.periods[i] = true
+		}
+	}
+
+	var recurring []Recurring
+	for _, key := range order {
+		s := payees[key]
+		// once per period, in every period
+		if len(s.periods) < recurringPeriods || len(s.amounts) != recurringPeriods {
+			continue
+		}
+		amount := median(s.amounts)
+		steady := amount > 0
+		for _, a := range s.amounts {
+			if math.Abs(a-amount) > recurringSpread*amount {
+				steady = false
+			}
+		}
+		if !steady {
+			continue
+		}
+		slices.Sort(s.days)
+		recurring = append(recurring, Recurring{Payee: key, Amount: float32(amount), Day: s.days[len(s.days)/2]})
+	}
+	return recurring
+}
+
+// paidIn finds the recurring payment among the transactions of the period
+func paidIn(r Recurring, current []models.Transaction) (float32, bool) {
+	for _, tx := range current {
+		if payeeKey(tx) == r.Payee {
+			return tx.Amount, true
+		}
+	}
+	return 0, false
+}
+
+// pastPace is the daily spending of the periods before period, besides the
+// recurring payments of the payees in skip
+func pastPace(period budget.Period, history []models.Transaction, skip map[string]bool) (float64, bool) {
+	start := period.Shift(-recurringPeriods).Start
+	if !slices.ContainsFunc(history, func(tx models.Transaction) bool { return tx.Date.Before(start) }) {
+		// the history does not reach back far enough to tell
+		return 0, false
+	}
+
+	var spent float64
+	for _, tx := range history {
+		if tx.IsTransfer || tx.Date.Before(start) || !tx.Date.Before(period.Start) || skip[payeeKey(tx)] {
+			continue
+		}
+		spent += float64(tx.Amount)
+	}
+	return spent / float64(days(start, period.Start)), true
+}
+
+// seasonality compares the daily spending of the same period a year before
+// with the daily spending of the year leading up to this period, leaving
+// out the recurring payments of the payees in skip
+func seasonality(period budget.Period, history []models.Transaction, skip map[string]bool) float64 {
+	if period.Kind == budget.Yearly {
+		// a year before is all the year there is to compare with
+		return 1
+	}
+	yearAgo := sameTimeLastYear(period)
+	if !slices.ContainsFunc(history, func(tx models.Transaction) bool { return tx.Date.Before(yearAgo.Start) }) {
+		// the history does not cover the whole period a year before
+		return 1
+	}
+	yearStart := period.Start.AddDate(-1, 0, 0)
+
+	var then, year float64
+	for _, tx := range history {
+		if tx.IsTransfer || skip[payeeKey(tx)] {
+			continue
+		}
+		if yearAgo.Contains(tx.Date) {
+			then += float64(tx.Amount)
+		}
+		if !tx.Date.Before(yearStart) && tx.Date.Before(period.Start) {
+			year += float64(tx.Amount)
+		}
+	}
+	if year <= 0 || then <= 0 {
+		return 1
+	}
+	factor := (then / float64(days(yearAgo.Start, yearAgo.End))) / (year / float64(days(yearStart, period.Start)))
+	return min(max(factor, minSeasonality), maxSeasonality)
+}
+
+// sameTimeLastYear is the period holding the day a year before period
+// starts
+func sameTimeLastYear(period budget.Period) budget.Period {
+	day := period.Start.AddDate(-1, 0, 0)
+	past := period.Shift(-1)
+	for !past.Contains(day) {
+		past = past.Shift(-1)
+	}
+	return past
+}
+
+// HistoryStart is the first day of the history Project needs for a period
+func HistoryStart(period budget.Period) time.Time {
+	start := period.Shift(-recurringPeriods - 1).Start
+	if year := sameTimeLastYear(period).Shift(-1).Start; year.Before(start) {
+		start = year
+	}
+	return start
+}
+
+// payeeKey groups the transactions of one payee
+func payeeKey(tx models.Transaction) string {
+	name := tx.Payee
+	if name == "" {
+		name = tx.Description
+	}
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func days(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
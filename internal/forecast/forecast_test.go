@@ -0,0 +1,176 @@
+package forecast
+
+import (
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func month(year int, m time.Month) budget.Period {
+	return budget.Containing(budget.Monthly, time.Time{}, date(year, m, 1))
+}
+
+func TestRecurringDate(t *testing.T) {
+	tests := []struct {
+		name   string
+		day    int
+		period budget.Period
+		want   time.Time
+	}{
+		{"first day", 0, month(2026, time.April), date(2026, time.April, 1)},
+		{"usual day", 14, month(2026, time.April), date(2026, time.April, 15)},
+		{"last day of a long month", 30, month(2026, time.March), date(2026, time.March, 31)},
+		{"past the end of a short month", 30, month(2026, time.February), date(2026, time.February, 28)},
+		{"leap February", 30, month(2028, time.February), date(2028, time.February, 29)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Recurring{Day: tt.day}).Date(tt.period); !got.Equal(tt.want) {
+				t.Errorf("Date = %s, want %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestProjectRecurringDue(t *testing.T) {
+	// rent on the 3rd of every month before April
+	var history []models.Transaction
+	for m := time.January; m <= time.March; m++ {
+		history = append(history, models.Transaction{Payee: "Landlord", Amount: 1000, Date: date(2026, m, 3)})
+	}
+	april := month(2026, time.April)
+
+	tests := []struct {
+		name  string
+		today time.Time
+		paid  bool
+		want  float32
+	}{
+		{"before its day", date(2026, time.April, 2), false, 1000},
+		{"late within the grace days", date(2026, time.April, 10), false, 1000},
+		{"too late is taken as skipped", date(2026, time.April, 11), false, 0},
+		{"paid this period", date(2026, time.April, 5), true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs := history
+			if tt.paid {
+				txs = append(txs[:len(txs):len(txs)], models.Transaction{Payee: "Landlord", Amount: 1000, Date: date(2026, time.April, 3)})
+			}
+			if got := Project(april, tt.today, txs).Recurring; got != tt.want {
+				t.Errorf("Recurring = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindRecurring(t *testing.T) {
+	april := month(2026, time.April)
+	monthly := func(payee string, amounts ...float32) []models.Transaction {
+		var txs []models.Transaction
+		for i, amount := range amounts {
+			txs = append(txs, models.Transaction{Payee: payee, Amount: amount, Date: date(2026, time.January+time.Month(i), 5)})
+		}
+		return txs
+	}
+
+	tests := []struct {
+		name    string
+		history []models.Transaction
+		want    []Recurring
+	}{
+		{"same amount every month", monthly("Streaming", 12.99, 12.99, 12.99), []Recurring{{Payee: "streaming", Amount: 12.99, Day: 4}}},
+		{"amount within the spread", monthly("Power", 80, 90, 95), []Recurring{{Payee: "power", Amount: 90, Day: 4}}},
+		{"amount beyond the spread", monthly("Power", 50, 90, 95), nil},
+		{"missing a month", monthly("Gym", 30, 30), nil},
+		{"twice in a month", append(monthly("Cafe", 4, 4, 4), models.Transaction{Payee: "Cafe", Amount: 4, Date: date(2026, time.March, 20)}), nil},
+		{"transfers do not count", func() []models.Transaction {
+			txs := monthly("Savings", 100, 100, 100)
+			for i := range txs {
+				txs[i].IsTransfer = true
+			}
+			return txs
+		}(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindRecurring(april, tt.history)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindRecurring = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("FindRecurring[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	april := month(2026, time.April)
+	spend := func(amount float32, day time.Time) models.Transaction {
+		return models.Transaction{Payee: "Shop", Amount: amount, Date: day}
+	}
+
+	tests := []struct {
+		name      string
+		today     time.Time
+		history   []models.Transaction
+		spent     float32
+		pace      float32
+		recurring float32
+	}{
+		{
+			// 100 in 10 days goes on at 10 a day for the 20 days left
+			name:    "pace so far without history",
+			today:   date(2026, time.April, 10),
+			history: []models.Transaction{spend(60, date(2026, time.April, 2)), spend(40, date(2026, time.April, 9))},
+			spent:   100, pace: 200,
+		},
+		{
+			name:  "transfers and later days are left out",
+			today: date(2026, time.April, 10),
+			history: []models.Transaction{
+				spend(100, date(2026, time.April, 2)),
+				{Payee: "Savings", Amount: 500, Date: date(2026, time.April, 3), IsTransfer: true},
+				spend(70, date(2026, time.April, 12)),
+			},
+			spent: 100, pace: 200,
+		},
+		{
+			name:    "period over",
+			today:   date(2026, time.April, 30),
+			history: []models.Transaction{spend(300, date(2026, time.April, 2))},
+			spent:   300,
+		},
+		{
+			name:  "recurring payment is projected on its own",
+			today: date(2026, time.April, 10),
+			history: []models.Transaction{
+				{Payee: "Insurance", Amount: 50, Date: date(2026, time.January, 20)},
+				{Payee: "Insurance", Amount: 50, Date: date(2026, time.February, 20)},
+				{Payee: "Insurance", Amount: 50, Date: date(2026, time.March, 20)},
+				spend(100, date(2026, time.April, 2)),
+			},
+			spent: 100, pace: 200, recurring: 50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Project(april, tt.today, tt.history)
+			if p.Spent != tt.spent || p.Pace != tt.pace || p.Recurring != tt.recurring {
+				t.Errorf("Project = spent %.2f, pace %.2f, recurring %.2f; want %.2f, %.2f, %.2f",
+					p.Spent, p.Pace, p.Recurring, tt.spent, tt.pace, tt.recurring)
+			}
+			if want := tt.spent + tt.pace + tt.recurring; p.Projected() != want {
+				t.Errorf("Projected = %.2f, want %.2f", p.Projected(), want)
+			}
+		})
+	}
+}
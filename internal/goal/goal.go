@@ -0,0 +1,66 @@
+// Package goal works out how a savings goal is doing: the monthly
+// contribution it still needs and whether it is on track for its date.
+package goal
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"time"
+)
+
+// Milestones are the shares of the target, in percent, that send an alert
+// once reached
+var Milestones = []int{25, 50, 75, 100}
+
+// Evaluate compares what was saved for a goal with a steady pace from its
+// start to its target date. A goal is on track when it saved at least what
+// that pace would have by today.
+func Evaluate(g models.Goal, saved float32, today time.Time) models.GoalStatus {
+	s := models.GoalStatus{Goal: g, Saved: saved}
+	today = day(today)
+	start, end := day(g.StartDate), day(g.TargetDate)
+
+	share := float32(1)
+	if total := end.Sub(start); total > 0 && today.Before(end) {
+		share = max(float32(today.Sub(start))/float32(total), 0)
+	}
+	s.Expected = g.Target * share
+
+	remaining := max(g.Target-saved, 0)
+	s.MonthsLeft = MonthsLeft(today, end)
+	switch {
+	case remaining == 0:
+	case s.MonthsLeft == 0:
+		// past due: all of it is needed now
+		s.Required = remaining
+	default:
+		s.Required = remaining / float32(s.MonthsLeft)
+	}
+	s.OnTrack = saved >= g.Target || saved >= s.Expected
+	return s
+}
+
+// MonthsLeft counts the months from today up to the target date, the
+// current month and the month of the target date included; 0 once the
+// date passed
+func MonthsLeft(today, target time.Time) int {
+	if day(target).Before(day(today)) {
+		return 0
+	}
+	return (target.Year()-today.Year())*12 + int(target.Month()-today.Month()) + 1
+}
+
+// Milestone is the highest milestone progress, in percent, reached; 0 for
+// none
+func Milestone(progress float32) int {
+	reached := 0
+	for _, m := range Milestones {
+		if progress >= float32(m) {
+			reached = m
+		}
+	}
+	return reached
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"peronal_finance_cli_manager/internal/models"
+)
+
+func init() {
+	Register(csvImporter{})
+}
+
+// csvImporter reads the app's own CSV layout
+type csvImporter struct{}
+
+func (csvImporter) Format() string {
+	return "csv"
+}
+
+func (csvImporter) Sniff(fileName string, head []byte) bool {
+	return strings.EqualFold(filepath.Ext(fileName), ".csv")
+}
+
+// Parse reads CSV rows.
+// CSV format: Category,Amount,Date, where the profile may say the first
+// column is the payee or the description instead of the category.
+// Optional Payee, Description, Account and Reference columns are picked up
+// by header name.
+// Only file level problems (bad header) are returned as error.
+func (csvImporter) Parse(r io.Reader, profile Profile, emit func(Record) error) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	if profile.Delimiter != "" {
+		reader.Comma = []rune(profile.Delimiter)[0]
+	}
+
+	// Read header
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	if len(header) < 3 {
+		return errors.New("CSV must have at least 3 columns: Category, Amount, Date")
+	}
+	optional := optionalColumns(header)
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		var row Record
+		switch {
+		case err != nil:
+			row = Record{Line: line, Err: err}
+		case len(record) < 3:
+			row = Record{
+				Line: line,
+				Err:  fmt.Errorf("expected at least 3 columns, got %d", len(record)),
+			}
+		default:
+			row = csvRow(line, record, optional, profile)
+		}
+
+		if err := emit(row); err != nil {
+			return err
+		}
+	}
+}
+
+func csvRow(line int, record []string, optional map[string]int, profile Profile) Record {
+	var tx models.Transaction
+	first := strings.TrimSpace(record[0])
+	switch profile.FirstColumn {
+	case ColumnPayee:
+		tx.Payee = first
+	case ColumnDescription:
+		tx.Description = first
+	default:
+		tx.Category.Name = first
+	}
+	if i, ok := optional["payee"]; ok && i < len(record) {
+		tx.Payee = record[i]
+	}
+	if i, ok := optional["description"]; ok && i < len(record) {
+		tx.Description = record[i]
+	}
+	if i, ok := optional["account"]; ok && i < len(record) {
+		tx.Account = record[i]
+	}
+	if i, ok := optional["reference"]; ok && i < len(record) {
+		tx.ImportID = record[i]
+	}
+
+	row := Record{Line: line, Transaction: tx}
+	row.Transaction.Amount, row.Err = ParseAmount(record[1])
+	if row.Err == nil {
+		row.Transaction.Date, row.Err = parseDate(record[2], profile)
+	}
+	if row.Err == nil && first == "" {
+		row.Err = fmt.Errorf("missing %s", firstColumn(profile))
+	}
+	return row
+}
+
+// firstColumn names what the first column holds under a profile
+func firstColumn(profile Profile) string {
+	if profile.FirstColumn == "" {
+		return ColumnCategory
+	}
+	return profile.FirstColumn
+}
+
+// optionalColumns maps the known optional headers to their column index
+func optionalColumns(header []string) map[string]int {
+	columns := make(map[string]int)
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "payee":
+			columns["payee"] = i
+		case "description", "memo":
+			columns["description"] = i
+		case "account":
+			columns["account"] = i
+		case "reference", "fitid":
+			columns["reference"] = i
+		}
+	}
+	return columns
+}
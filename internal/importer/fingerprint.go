@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"peronal_finance_cli_manager/internal/models"
+)
+
+// Fingerprint returns the import identity of a transaction. A bank reference
+// is used as is; otherwise date, amount, payee and account are hashed.
+func Fingerprint(tx models.Transaction) string {
+	if tx.ImportID != "" {
+		return tx.ImportID
+	}
+
+	payee := tx.Payee
+	if payee == "" {
+		payee = tx.Description
+	}
+	if payee == "" {
+		// older CSV exports only carry the merchant in the category column
+		payee = tx.Category.Name
+	}
+
+	key := fmt.Sprintf("%s|%.2f|%s|%s",
+		tx.Date.Format("2006-01-02"),
+		tx.Amount,
+		strings.ToLower(strings.TrimSpace(payee)),
+		strings.ToLower(strings.TrimSpace(tx.Account)),
+	)
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// importIDs assigns the import identity of every valid row in a file.
+// Identical rows inside the same file get an occurrence suffix so two equal
+// purchases on one day are not treated as duplicates of each other.
+type importIDs struct {
+	seen map[string]int
+}
+
+func newImportIDs() *importIDs {
+	return &importIDs{seen: make(map[string]int)}
+}
+
+func (ids *importIDs) assign(rec *Record) {
+	id := Fingerprint(rec.Transaction)
+	ids.seen[id]++
+	if n := ids.seen[id]; n > 1 {
+		id = fmt.Sprintf("%s#%d", id, n)
+	}
+	rec.Transaction.ImportID = id
+}
+
+// Refingerprint gives a row changed during review the identity of its new
+// content, with the first occurrence suffix not taken by another row of
+// the file, so it stays apart from identical rows
+func Refingerprint(tx models.Transaction, taken map[string]bool) string {
+	tx.ImportID = ""
+	base := Fingerprint(tx)
+	id := base
+	for n := 2; taken[id]; n++ {
+		id = fmt.Sprintf("%s#%d", base, n)
+	}
+	return id
+}
@@ -0,0 +1,36 @@
+package importer
+
+import (
+	"peronal_finance_cli_manager/internal/models"
+	"testing"
+	"time"
+)
+
+func TestRefingerprint(t *testing.T) {
+	coffee := models.Transaction{
+		Amount: 4.5,
+		Date:   time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		Payee:  "Cafe",
+	}
+	base := Fingerprint(coffee)
+
+	tests := []struct {
+		name  string
+		taken map[string]bool
+		want  string
+	}{
+		{"unique row keeps the bare fingerprint", nil, base},
+		{"identical row gets the next occurrence", map[string]bool{base: true}, base + "#2"},
+		{"taken occurrences are skipped", map[string]bool{base: true, base + "#2": true}, base + "#3"},
+		{"a free first occurrence is reused", map[string]bool{base + "#2": true}, base},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := coffee
+			tx.ImportID = "sha256:stale"
+			if got := Refingerprint(tx, tt.taken); got != tt.want {
+				t.Errorf("Refingerprint = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"peronal_finance_cli_manager/internal/models"
+)
+
+// Importer reads one statement file format. Adding a format means
+// implementing Importer and registering it with Register.
+type Importer interface {
+	// Format is the key the importer is registered under, e.g. "csv"
+	Format() string
+	// Sniff reports whether a file looks like this format, judging by its
+	// name and first bytes
+	Sniff(fileName string, head []byte) bool
+	// Parse reads the file and hands every row to emit as soon as it is
+	// read. Problems with a single row go into Record.Err; only problems
+	// with the file as a whole are returned.
+	Parse(r io.Reader, profile Profile, emit func(Record) error) error
+}
+
+// Record is one row of a statement file. Err is set when the row could
+// not be turned into a transaction; the rest of the file still parses.
+type Record struct {
+	Line        int
+	Transaction models.Transaction
+	Err         error
+}
+
+// sniffSize is how many bytes of a file are handed to Sniff
+const sniffSize = 512
+
+var registry []Importer
+
+// Register adds an importer. Importers registered first are sniffed first.
+func Register(imp Importer) {
+	registry = append(registry, imp)
+}
+
+// Lookup returns the importer registered for format
+func Lookup(format string) (Importer, error) {
+	for _, imp := range registry {
+		if imp.Format() == format {
+			return imp, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported file format '%s'", format)
+}
+
+// Formats returns the registered format names
+func Formats() []string {
+	formats := make([]string, 0, len(registry))
+	for _, imp := range registry {
+		formats = append(formats, imp.Format())
+	}
+	return formats
+}
+
+// Detect returns the importer for a file: the profile's format when it
+// names one, otherwise the first importer whose Sniff accepts the file.
+func Detect(filePath string, profile Profile) (Importer, error) {
+	if profile.Format != "" {
+		return Lookup(profile.Format)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func(file *os.File) {
+		err := file.Close()
+		if err != nil {
+
+		}
+	}(file)
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	for _, imp := range registry {
+		if imp.Sniff(filePath, head[:n]) {
+			return imp, nil
+		}
+	}
+	return nil, errors.New("unsupported file format")
+}
+
+// dateLayouts are the date formats accepted in statement files
+var dateLayouts = []string{
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02",
+	"01/02/2006",
+	"1/2/06",
+	"02.01.2006",
+}
+
+// StreamFile parses a statement file with the importer matching it and
+// hands every record to emit as soon as it is read, after filling in what
+// the profile adds to each row: the account and import identity. Parsing
+// stops with emit's error if it returns one. progress, when set, is called
+// with the number of bytes read so far and the file size.
+func StreamFile(filePath string, profile Profile, emit func(Record) error, progress func(read, size int64)) error {
+	imp, err := Detect(filePath, profile)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func(file *os.File) {
+		err := file.Close()
+		if err != nil {
+
+		}
+	}(file)
+
+	var r io.Reader = file
+	if progress != nil {
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		r = &progressReader{r: file, size: info.Size(), report: progress}
+	}
+
+	ids := newImportIDs()
+	return imp.Parse(r, profile, func(rec Record) error {
+		if rec.Err == nil {
+			if rec.Transaction.Account == "" {
+				rec.Transaction.Account = profile.Account
+			}
+			ids.assign(&rec)
+		}
+		return emit(rec)
+	})
+}
+
+// progressReader reports how far through a file the parser is
+type progressReader struct {
+	r      io.Reader
+	read   int64
+	size   int64
+	report func(read, size int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	p.report(p.read, p.size)
+	return n, err
+}
+
+// ParseAmount parses an amount column
+func ParseAmount(value string) (float32, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 32)
+	if err != nil {
+		return 0, errors.New("invalid amount: " + value)
+	}
+	return float32(amount), nil
+}
+
+// ParseDate parses a date column in any of the accepted layouts
+func ParseDate(value string) (time.Time, error) {
+	return parseDate(value, DefaultProfile)
+}
+
+func parseDate(value string, profile Profile) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	layouts := dateLayouts
+	if profile.DateLayout != "" {
+		layouts = append([]string{profile.DateLayout}, dateLayouts...)
+	}
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date: " + value)
+}
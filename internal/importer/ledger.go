@@ -0,0 +1,296 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"peronal_finance_cli_manager/internal/export"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+	"unicode"
+)
+
+func init() {
+	Register(ledgerImporter{})
+}
+
+// ledgerImporter reads ledger and hledger journals, including the ones
+// written by export.WriteLedger
+type ledgerImporter struct{}
+
+func (ledgerImporter) Format() string {
+	return "ledger"
+}
+
+func (ledgerImporter) Sniff(fileName string, head []byte) bool {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".ledger", ".journal", ".hledger":
+		return true
+	}
+	return false
+}
+
+// ledgerEntry is a journal entry being read
+type ledgerEntry struct {
+	line     int
+	date     string
+	payee    string
+	importID string
+	tags     string
+	desc     string
+	currency string
+	transfer bool
+	split    string
+	postings []ledgerPosting
+}
+
+type ledgerPosting struct {
+	account string
+	amount  string
+}
+
+// Parse reads a journal. Every posting to an Expenses or Income account
+// becomes a transaction of that category; the other posting is the bank
+// account. The entry tags written by export.WriteLedger fill the fields
+// the postings have no place for. Budgets declared on account directives
+// are kept on the category. Directives other than account are ignored.
+func (ledgerImporter) Parse(r io.Reader, profile Profile, emit func(Record) error) error {
+	budgets := make(map[string]float32)
+	var entry *ledgerEntry
+	var account string
+
+	flush := func() error {
+		if entry == nil {
+			return nil
+		}
+		for _, rec := range ledgerRecords(entry, budgets, profile) {
+			if err := emit(rec); err != nil {
+				return err
+			}
+		}
+		entry = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if text == "" {
+			if err := flush(); err != nil {
+				return err
+			}
+			account = ""
+			continue
+		}
+
+		indented := text[0] == ' ' || text[0] == '\t'
+		if !indented {
+			if err := flush(); err != nil {
+				return err
+			}
+			account = ""
+
+			switch {
+			case unicode.IsDigit(rune(text[0])):
+				entry = parseLedgerHeader(line, text)
+			case strings.HasPrefix(text, "account "):
+				account = strings.TrimSpace(strings.TrimPrefix(text, "account "))
+			}
+			continue
+		}
+
+		body := strings.TrimSpace(text)
+		if key, value, ok := ledgerTag(body); ok {
+			switch {
+			case entry != nil && key == export.ImportIDKey:
+				entry.importID = value
+			case entry != nil && key == export.TagsKey:
+				entry.tags = value
+			case entry != nil && key == export.DescriptionKey:
+				entry.desc = value
+			case entry != nil && key == export.CurrencyKey:
+				entry.currency = value
+			case entry != nil && key == export.TransferKey:
+				entry.transfer = strings.EqualFold(value, "true")
+			case entry != nil && key == export.SplitKey:
+				entry.split = value
+			case account != "" && key == export.BudgetKey:
+				if budget, err := ParseAmount(value); err == nil {
+					budgets[account] = budget
+				}
+			}
+			continue
+		}
+		if entry != nil && !strings.HasPrefix(body, ";") {
+			entry.postings = append(entry.postings, parseLedgerPosting(body))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// parseLedgerHeader reads "DATE[=DATE2] [*|!] [(CODE)] PAYEE [  ; comment]"
+func parseLedgerHeader(line int, text string) *ledgerEntry {
+	text = strings.ReplaceAll(text, "\t", "  ")
+	text, _, _ = strings.Cut(text, "  ;")
+	date, rest, _ := strings.Cut(strings.TrimSpace(text), " ")
+	date, _, _ = strings.Cut(date, "=")
+
+	rest = strings.TrimSpace(rest)
+	rest = strings.TrimLeft(rest, "*! ")
+	if strings.HasPrefix(rest, "(") {
+		if end := strings.Index(rest, ")"); end >= 0 {
+			rest = rest[end+1:]
+		}
+	}
+
+	return &ledgerEntry{
+		line:  line,
+		date:  strings.ReplaceAll(date, "/", "-"),
+		payee: strings.TrimSpace(rest),
+	}
+}
+
+// parseLedgerPosting reads "[*|!] ACCOUNT  [AMOUNT] [; comment]"; the
+// account ends at two spaces or a tab
+func parseLedgerPosting(body string) ledgerPosting {
+	body, _, _ = strings.Cut(body, ";")
+	body = strings.TrimLeft(body, "*! ")
+
+	var p ledgerPosting
+	end := strings.Index(body, "  ")
+	if tab := strings.Index(body, "\t"); tab >= 0 && (end < 0 || tab < end) {
+		end = tab
+	}
+	if end < 0 {
+		p.account = strings.TrimSpace(body)
+	} else {
+		p.account = strings.TrimSpace(body[:end])
+		p.amount = strings.TrimSpace(body[end:])
+	}
+	// virtual postings are written as (account) or [account]
+	p.account = strings.Trim(p.account, "()[]")
+	return p
+}
+
+// ledgerTag reads a "; key: value" comment
+func ledgerTag(body string) (string, string, bool) {
+	if !strings.HasPrefix(body, ";") {
+		return "", "", false
+	}
+	key, value, ok := strings.Cut(strings.TrimSpace(body[1:]), ":")
+	if !ok || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(value), true
+}
+
+// ledgerRecords turns an entry into one record per category posting. The
+// postings of one entry are the parts of a split: like a rule split, the
+// first part keeps the import identity and the others get "/2", "/3"…
+func ledgerRecords(entry *ledgerEntry, budgets map[string]float32, profile Profile) []Record {
+	fail := func(err error) []Record {
+		return []Record{{Line: entry.line, Err: err}}
+	}
+
+	date, err := parseDate(entry.date, profile)
+	if err != nil {
+		return fail(err)
+	}
+
+	// an elided amount balances the others
+	var sum float32
+	elided := -1
+	amounts := make([]float32, len(entry.postings))
+	for i, p := range entry.postings {
+		if p.amount == "" {
+			if elided >= 0 {
+				return fail(errors.New("more than one posting without amount"))
+			}
+			elided = i
+			continue
+		}
+		amount, err := parseLedgerAmount(p.amount)
+		if err != nil {
+			return fail(err)
+		}
+		amounts[i] = amount
+		sum += amount
+	}
+	if elided >= 0 {
+		amounts[elided] = -sum
+	}
+
+	var account string
+	type categoryPosting struct {
+		name    string
+		account string
+		amount  float32
+	}
+	var categories []categoryPosting
+	for i, p := range entry.postings {
+		name, ok := export.CategoryFromAccount(p.account)
+		if !ok {
+			if account == "" {
+				account = export.AccountFromAsset(p.account)
+			}
+			continue
+		}
+		categories = append(categories, categoryPosting{name: name, account: p.account, amount: amounts[i]})
+	}
+	if len(categories) == 0 {
+		return fail(fmt.Errorf("no %s or %s posting", export.ExpensesRoot, export.IncomeRoot))
+	}
+
+	records := make([]Record, 0, len(categories))
+	for i, c := range categories {
+		amount, kind := c.amount, ""
+		if strings.HasPrefix(c.account, export.IncomeRoot) {
+			// income is booked negative in a journal
+			amount, kind = -amount, models.KindIncome
+		}
+
+		tx := models.Transaction{
+			Category:    models.Category{Name: c.name, Kind: kind, Budget: budgets[c.account]},
+			Amount:      amount,
+			Date:        date,
+			Payee:       entry.payee,
+			Description: entry.desc,
+			Account:     account,
+			Currency:    entry.currency,
+			Tags:        entry.tags,
+			IsTransfer:  entry.transfer,
+			SplitGroup:  entry.split,
+		}
+		tx.ImportID = entry.importID
+		if i > 0 && entry.importID != "" {
+			tx.ImportID = fmt.Sprintf("%s/%d", entry.importID, i+1)
+		}
+		if len(categories) > 1 && tx.SplitGroup == "" {
+			tx.SplitGroup = entry.importID
+		}
+		records = append(records, Record{Line: entry.line, Transaction: tx})
+	}
+	return records
+}
+
+// parseLedgerAmount reads an amount with an optional commodity before or
+// after it, e.g. "12.50", "-12.50 USD" or "$1,200.00"
+func parseLedgerAmount(value string) (float32, error) {
+	value, _, _ = strings.Cut(value, "@")
+	number := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == '.' || r == '-' {
+			return r
+		}
+		return -1
+	}, value)
+	amount, err := ParseAmount(number)
+	if err != nil {
+		return 0, errors.New("invalid amount: " + value)
+	}
+	return amount, nil
+}
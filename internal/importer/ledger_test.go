@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"bytes"
+	"peronal_finance_cli_manager/internal/export"
+	"peronal_finance_cli_manager/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseLedger reads a journal into its records
+func parseLedger(t *testing.T, journal string) []Record {
+	t.Helper()
+	var records []Record
+	err := ledgerImporter{}.Parse(strings.NewReader(journal), Profile{}, func(rec Record) error {
+		records = append(records, rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestLedgerRoundTrip(t *testing.T) {
+	categories := []models.Category{
+		{ID: 1, Name: "Food", Kind: models.KindExpense, Budget: 300},
+		{ID: 2, Name: "Household", Kind: models.KindExpense},
+		{ID: 3, Name: models.IncomeCategory, Kind: models.KindIncome},
+		{ID: 4, Name: "Freelance", Kind: models.KindIncome},
+		{ID: 5, Name: "Savings", Kind: models.KindExpense},
+	}
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		txs  []models.Transaction
+		// want are the records read back, the exported transactions when nil
+		want []models.Transaction
+	}{
+		{
+			name: "expense with its fields",
+			txs: []models.Transaction{{CategoryID: 1, Amount: 12.5, Date: day, Payee: "Bakery", Description: "BAKERY 42",
+				Account: "Checking", Currency: "EUR", Tags: "daily,cash", ImportID: "sha256:a"}},
+		},
+		{
+			name: "income on the root and a named income account",
+			txs: []models.Transaction{
+				{CategoryID: 3, Amount: 2000, Date: day, Payee: "Employer", Account: "Checking", ImportID: "sha256:b"},
+				{CategoryID: 4, Amount: 350, Date: day.AddDate(0, 0, 1), Payee: "Client", Account: "Checking", ImportID: "sha256:c"},
+			},
+		},
+		{
+			name: "transfer",
+			txs: []models.Transaction{{CategoryID: 5, Amount: 100, Date: day, Payee: "To savings", Account: "Checking",
+				IsTransfer: true, ImportID: "sha256:d"}},
+		},
+		{
+			name: "split parts share one entry",
+			txs: []models.Transaction{
+				{CategoryID: 1, Amount: 60, Date: day, Payee: "Market", Account: "Visa", ImportID: "sha256:e", SplitGroup: "sha256:e"},
+				{CategoryID: 2, Amount: 40, Date: day, Payee: "Market", Account: "Visa", ImportID: "sha256:e/2", SplitGroup: "sha256:e"},
+			},
+		},
+		{
+			name: "manual split keeps its group",
+			txs: []models.Transaction{
+				{CategoryID: 1, Amount: 7, Date: day, Payee: "Kiosk", SplitGroup: "manual-1"},
+				{CategoryID: 2, Amount: 3, Date: day, Payee: "Kiosk", SplitGroup: "manual-1"},
+			},
+		},
+		{
+			name: "description is kept on one line",
+			txs: []models.Transaction{{CategoryID: 2, Amount: 9.99, Date: day, Payee: "Hardware",
+				Description: "HARDWARE\n  STORE 7", Account: "Checking"}},
+			want: []models.Transaction{{CategoryID: 2, Amount: 9.99, Date: day, Payee: "Hardware",
+				Description: "HARDWARE STORE 7", Account: "Checking"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var journal bytes.Buffer
+			if err := export.WriteLedger(&journal, categories, tt.txs, export.Options{}); err != nil {
+				t.Fatal(err)
+			}
+			records := parseLedger(t, journal.String())
+
+			want := tt.want
+			if want == nil {
+				want = tt.txs
+			}
+			if len(records) != len(want) {
+				t.Fatalf("read %d records, want %d:\n%s", len(records), len(want), journal.String())
+			}
+			for i, rec := range records {
+				if rec.Err != nil {
+					t.Fatalf("record %d: %v", i, rec.Err)
+				}
+				got, w := rec.Transaction, want[i]
+				c := categories[w.CategoryID-1]
+				if got.Category.Name != c.Name || (got.Category.Kind == models.KindIncome) != (c.Kind == models.KindIncome) {
+					t.Errorf("record %d category = %s (%s), want %s (%s)", i, got.Category.Name, got.Category.Kind, c.Name, c.Kind)
+				}
+				if got.Category.Budget != c.Budget {
+					t.Errorf("record %d budget = %.2f, want %.2f", i, got.Category.Budget, c.Budget)
+				}
+				got.Category, got.CategoryID = models.Category{}, w.CategoryID
+				if !reflect.DeepEqual(got, w) {
+					t.Errorf("record %d = %+v\nwant %+v", i, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLedger(t *testing.T) {
+	tests := []struct {
+		name    string
+		journal string
+		want    []float32
+		wantErr string
+	}{
+		{
+			name:    "elided amount balances the entry",
+			journal: "2026/03/04 * Shop\n    Expenses:Food  12.50\n    Assets:Checking\n",
+			want:    []float32{12.5},
+		},
+		{
+			name:    "elided category amount",
+			journal: "2026-03-04 Shop\n    Assets:Checking  -20\n    Expenses:Food\n",
+			want:    []float32{20},
+		},
+		{
+			name:    "commodity and thousands separator",
+			journal: "2026-03-04 * (42) Landlord  ; rent\n\tExpenses:Rent\t$1,200.00\n\tAssets:Checking\t$-1,200.00\n",
+			want:    []float32{1200},
+		},
+		{
+			name:    "income is booked negative",
+			journal: "2026-03-04 * Employer\n    Assets:Checking  2000 EUR\n    Income:Salary  -2000 EUR\n",
+			want:    []float32{2000},
+		},
+		{
+			name:    "two elided amounts",
+			journal: "2026-03-04 * Shop\n    Expenses:Food\n    Assets:Checking\n",
+			wantErr: "more than one posting without amount",
+		},
+		{
+			name:    "no category posting",
+			journal: "2026-03-04 * Move\n    Assets:Savings  100\n    Assets:Checking  -100\n",
+			wantErr: "no Expenses or Income posting",
+		},
+		{
+			name:    "invalid date",
+			journal: "2026-13-04 * Shop\n    Expenses:Food  1\n    Assets:Checking\n",
+			wantErr: "invalid date",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := parseLedger(t, tt.journal)
+			if tt.wantErr != "" {
+				if len(records) != 1 || records[0].Err == nil || !strings.Contains(records[0].Err.Error(), tt.wantErr) {
+					t.Fatalf("records = %+v, want the error %q", records, tt.wantErr)
+				}
+				return
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("read %d records, want %d", len(records), len(tt.want))
+			}
+			for i, rec := range records {
+				if rec.Err != nil {
+					t.Fatalf("record %d: %v", i, rec.Err)
+				}
+				if rec.Transaction.Amount != tt.want[i] {
+					t.Errorf("record %d amount = %.2f, want %.2f", i, rec.Transaction.Amount, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/aclindsa/ofxgo"
+
+	"peronal_finance_cli_manager/internal/models"
+)
+
+// IncomeCategory receives money coming in from formats without categories
+const IncomeCategory = models.IncomeCategory
+
+func init() {
+	Register(ofxImporter{})
+}
+
+// ofxImporter reads OFX and QFX bank and credit card statements
+type ofxImporter struct{}
+
+func (ofxImporter) Format() string {
+	return "ofx"
+}
+
+func (ofxImporter) Sniff(fileName string, head []byte) bool {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".ofx", ".qfx":
+		return true
+	}
+	return bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>"))
+}
+
+// Parse reads the bank and credit card statements of an OFX/QFX file.
+// Debits become positive expenses without a category, credits go to Income,
+// and the FITID is kept as import identity. The OFX document is decoded as
+// a whole; only the rows are streamed.
+func (ofxImporter) Parse(r io.Reader, profile Profile, emit func(Record) error) error {
+	resp, err := ofxgo.DecodeResponse(r)
+	if err != nil {
+		return err
+	}
+
+	line := 0
+	add := func(account string, list *ofxgo.TransactionList) error {
+		if list == nil {
+			return nil
+		}
+		for _, st := range list.Transactions {
+			amount, _ := st.TrnAmt.Rat.Float64()
+
+			payee := string(st.Name)
+			if st.Payee != nil {
+				payee = string(st.Payee.Name)
+			}
+
+			tx := models.Transaction{
+				Amount:   float32(-amount),
+				Date:     st.DtPosted.Time,
+				Payee:    strings.TrimSpace(payee),
+				Account:  account,
+				ImportID: string(st.FiTID),
+			}
+			if amount > 0 {
+				tx.Amount = float32(amount)
+				tx.Category = models.Category{Name: IncomeCategory}
+			}
+
+			line++
+			if err := emit(Record{Line: line, Transaction: tx}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, msg := range resp.Bank {
+		if stmt, ok := msg.(*ofxgo.StatementResponse); ok {
+			if err := add(string(stmt.BankAcctFrom.AcctID), stmt.BankTranList); err != nil {
+				return err
+			}
+		}
+	}
+	for _, msg := range resp.CreditCard {
+		if stmt, ok := msg.(*ofxgo.CCStatementResponse); ok {
+			if err := add(string(stmt.CCAcctFrom.AcctID), stmt.BankTranList); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
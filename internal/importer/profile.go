@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Profile describes how the statement files of one bank are read.
+type Profile struct {
+	Name string `json:"name"`
+	// Format forces a parser; empty means detect it from the file extension
+	Format string `json:"format,omitempty"`
+	// Account is stored on every row that does not name its own account
+	Account string `json:"account,omitempty"`
+	// Delimiter is the CSV field separator, a comma when empty
+	Delimiter string `json:"delimiter,omitempty"`
+	// DateLayout is tried before the built-in date formats
+	DateLayout string `json:"date_layout,omitempty"`
+	// FirstColumn says what the first CSV column holds: ColumnCategory
+	// (the default), ColumnPayee or ColumnDescription. Rows without a
+	// category are categorized by the rules, or land in the inbox.
+	FirstColumn string `json:"first_column,omitempty"`
+	// Verbatim stores the rows as read: payees are not normalized to
+	// their merchant and the rules do not run
+	Verbatim bool `json:"verbatim,omitempty"`
+}
+
+// What the first CSV column can hold
+const (
+	ColumnCategory    = "category"
+	ColumnPayee       = "payee"
+	ColumnDescription = "description"
+)
+
+// DefaultProfile reads the app's own CSV layout and detects other formats
+var DefaultProfile = Profile{Name: "default"}
+
+// MerchantProfile reads bank CSVs that put the merchant where the app's
+// layout has the category
+var MerchantProfile = Profile{Name: "merchant", FirstColumn: ColumnPayee}
+
+// JournalProfile loads a ledger journal written by the export as it is, so
+// the round trip keeps every row unchanged
+var JournalProfile = Profile{Name: "journal", Format: "ledger", Verbatim: true}
+
+var profiles = map[string]Profile{
+	DefaultProfile.Name:  DefaultProfile,
+	MerchantProfile.Name: MerchantProfile,
+	JournalProfile.Name:  JournalProfile,
+}
+
+// LoadProfiles reads additional profiles from a JSON file holding a list of
+// profiles. A missing file is not an error.
+func LoadProfiles(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var loaded []Profile
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("invalid profiles file %s: %w", filePath, err)
+	}
+	for _, p := range loaded {
+		if p.Name == "" {
+			return fmt.Errorf("invalid profiles file %s: profile without name", filePath)
+		}
+		switch p.FirstColumn {
+		case "", ColumnCategory, ColumnPayee, ColumnDescription:
+		default:
+			return fmt.Errorf("invalid profiles file %s: profile %s: unknown first_column '%s'",
+				filePath, p.Name, p.FirstColumn)
+		}
+		profiles[p.Name] = p
+	}
+	return nil
+}
+
+// GetProfile returns the profile registered under name
+func GetProfile(name string) (Profile, error) {
+	p, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown import profile '%s'", name)
+	}
+	return p, nil
+}
+
+// ProfileNames returns the names of all known profiles, sorted
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FileHash returns the sha256 of a statement file's content
+func FileHash(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer func(file *os.File) {
+		err := file.Close()
+		if err != nil {
+
+		}
+	}(file)
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
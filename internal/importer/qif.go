@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"peronal_finance_cli_manager/internal/models"
+)
+
+func init() {
+	Register(qifImporter{})
+}
+
+// qifImporter reads Quicken interchange bank exports
+type qifImporter struct{}
+
+func (qifImporter) Format() string {
+	return "qif"
+}
+
+func (qifImporter) Sniff(fileName string, head []byte) bool {
+	return strings.EqualFold(filepath.Ext(fileName), ".qif") ||
+		bytes.HasPrefix(bytes.TrimSpace(head), []byte("!Type:"))
+}
+
+// Parse reads a QIF bank export. Every record ends with a "^" line;
+// D is the date, T the amount, P the payee and L the category. Like OFX,
+// outgoing money becomes a positive expense and incoming money goes to
+// Income unless the record names a category.
+func (qifImporter) Parse(r io.Reader, profile Profile, emit func(Record) error) error {
+	var fields map[byte]string
+	start := 0
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "!") {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[byte]string)
+			start = line
+		}
+
+		if text != "^" {
+			fields[text[0]] = text[1:]
+			continue
+		}
+
+		if err := emit(qifRow(start, fields, profile)); err != nil {
+			return err
+		}
+		fields = nil
+	}
+
+	return scanner.Err()
+}
+
+func qifRow(line int, fields map[byte]string, profile Profile) Record {
+	row := Record{Line: line}
+
+	amount, err := ParseAmount(strings.ReplaceAll(fields['T'], ",", ""))
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	// QIF writes two digit years as 12/25'25
+	date, err := parseDate(strings.ReplaceAll(fields['D'], "'", "/"), profile)
+	if err != nil {
+		row.Err = err
+		return row
+	}
+
+	row.Transaction = models.Transaction{
+		Amount:   -amount,
+		Date:     date,
+		Payee:    strings.TrimSpace(fields['P']),
+		Category: models.Category{Name: strings.TrimSpace(fields['L'])},
+	}
+	if amount > 0 {
+		row.Transaction.Amount = amount
+		if row.Transaction.Category.Name == "" {
+			row.Transaction.Category.Name = IncomeCategory
+		}
+	}
+
+	return row
+}
@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"slices"
+)
+
+// errImportStopped ends a file stream when the commit gave up on it
+var errImportStopped = errors.New("import stopped")
+
+// NewBatch describes an import of filePath before it is committed
+func NewBatch(filePath string, profile Profile) (*models.ImportBatch, error) {
+	hash, err := FileHash(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ImportBatch{
+		FileName: filePath,
+		FileHash: hash,
+		Profile:  profile.Name,
+	}, nil
+}
+
+// ImportFile streams a statement file straight into the database as one
+// batch, without a review step. Rows that were already imported are
+// skipped; rows that cannot be parsed are counted as failed and reported in
+// the summary. A file that cannot be read to the end imports nothing.
+func ImportFile(ctx context.Context, filePath string, profile Profile, progress func(done, total int)) (models.ImportSummary, error) {
+	batch, err := NewBatch(filePath, profile)
+	if err != nil {
+		return models.ImportSummary{}, err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var parseErrors []string
+	rows := func(yield func(models.Transaction) bool) {
+		err := StreamFile(filePath, profile, func(rec Record) error {
+			if rec.Err != nil {
+				batch.Failed++
+				parseErrors = append(parseErrors, fmt.Sprintf("line %d: %v", rec.Line, rec.Err))
+				return nil
+			}
+			if !yield(rec.Transaction) {
+				return errImportStopped
+			}
+			return nil
+		}, nil)
+		if err != nil && !errors.Is(err, errImportStopped) {
+			cancel(err)
+		}
+	}
+
+	summary, err := db.CommitImport(ctx, batch, rows, 0, profile.Verbatim, progress)
+	if err != nil {
+		return summary, err
+	}
+
+	summary.Failed += len(parseErrors)
+	summary.Errors = append(parseErrors, summary.Errors...)
+	return summary, nil
+}
+
+// Preview is a parsed statement file waiting to be reviewed
+type Preview struct {
+	FilePath string
+	Profile  Profile
+	Records  []Record
+	// Imported and Categories hold the import identities and category
+	// names that are already in the database
+	Imported   map[string]bool
+	Categories map[string]bool
+}
+
+// ReadPreview parses a whole statement file for review and looks up which
+// of its rows were imported before. progress, when set, gets the bytes read
+// so far, the file size and the rows parsed so far. Every row is kept in
+// memory for review, so a preview grows with its file; files too large to
+// review are loaded with ImportFile, which streams them.
+func ReadPreview(ctx context.Context, filePath string, profile Profile, progress func(read, size int64, rows int)) (*Preview, error) {
+	p := &Preview{FilePath: filePath, Profile: profile}
+
+	var ids []string
+	err := StreamFile(filePath, profile, func(rec Record) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p.Records = append(p.Records, rec)
+		if rec.Err == nil {
+			ids = append(ids, rec.Transaction.ImportID)
+		}
+		return nil
+	}, func(read, size int64) {
+		if progress != nil {
+			progress(read, size, len(p.Records))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.Imported, err = db.ImportedIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	cats, err := db.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+	p.Categories = make(map[string]bool, len(cats))
+	for _, c := range cats {
+		p.Categories[c.Name] = true
+	}
+
+	return p, nil
+}
+
+// Commit writes the rows accepted during review as one batch. rejected is
+// the number of rows the user left out and failures the rows that could
+// not be parsed; both are recorded on the batch and in the summary.
+func (p *Preview) Commit(
+	ctx context.Context,
+	accepted []models.Transaction,
+	rejected int,
+	failures []string,
+	progress func(done, total int),
+) (models.ImportSummary, error) {
+	batch, err := NewBatch(p.FilePath, p.Profile)
+	if err != nil {
+		return models.ImportSummary{}, err
+	}
+	batch.Skipped = rejected
+	batch.Failed = len(failures)
+
+	summary, err := db.CommitImport(ctx, batch, slices.Values(accepted), len(accepted), p.Profile.Verbatim, progress)
+	if err != nil {
+		return summary, err
+	}
+
+	summary.Skipped += rejected
+	summary.Failed += len(failures)
+	summary.Errors = append(failures, summary.Errors...)
+	return summary, nil
+}
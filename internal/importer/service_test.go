@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/export"
+	"peronal_finance_cli_manager/internal/models"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB points db.DB at a fresh, migrated database for the test
+func openTestDB(t *testing.T) {
+	t.Helper()
+	conn, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := db.DB
+	db.DB = conn
+	t.Cleanup(func() {
+		db.DB = previous
+		if sqlDB, err := conn.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportFileJournalRoundTrip(t *testing.T) {
+	openTestDB(t)
+
+	var categories []models.Category
+	for _, c := range []models.Category{
+		{Name: "Food", Kind: models.KindExpense, Budget: 300},
+		{Name: "Household", Kind: models.KindExpense, Budget: 100},
+		{Name: models.IncomeCategory, Kind: models.KindIncome},
+		{Name: "Savings", Kind: models.KindExpense, Budget: 500},
+	} {
+		if err := db.DB.Create(&c).Error; err != nil {
+			t.Fatal(err)
+		}
+		categories = append(categories, c)
+	}
+	// a rule that would rename, tag and split the market rows
+	rule := models.CategoryRule{
+		Enabled:    true,
+		Match:      models.MatchAll,
+		Conditions: []models.RuleCondition{{Field: models.FieldPayee, Op: models.OpContains, Value: "market"}},
+		Actions: []models.RuleAction{
+			{Type: models.ActionPayee, Value: "Supermarket"},
+			{Type: models.ActionTag, Value: "groceries"},
+			{Type: models.ActionSplit, Splits: []models.RuleSplit{{Category: "Food", Percent: 50}, {Category: "Household", Percent: 50}}},
+		},
+	}
+	if err := db.DB.Create(&rule).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	txs := []models.Transaction{
+		{CategoryID: categories[0].ID, Amount: 60, Date: day, Payee: "Market", Description: "MARKET 12",
+			Account: "Visa", Currency: "EUR", Tags: "weekly", ImportID: "sha256:a", SplitGroup: "sha256:a"},
+		{CategoryID: categories[1].ID, Amount: 40, Date: day, Payee: "Market", Description: "MARKET 12",
+			Account: "Visa", Currency: "EUR", Tags: "weekly", ImportID: "sha256:a/2", SplitGroup: "sha256:a"},
+		{CategoryID: categories[0].ID, Amount: 12.5, Date: day, Payee: "Fish Market", Account: "Checking", ImportID: "sha256:b"},
+		{CategoryID: categories[2].ID, Amount: 2000, Date: day.AddDate(0, 0, 1), Payee: "Employer", Account: "Checking", ImportID: "sha256:c"},
+		{CategoryID: categories[3].ID, Amount: 100, Date: day.AddDate(0, 0, 2), Payee: "To savings", Account: "Checking",
+			IsTransfer: true, ImportID: "sha256:d"},
+	}
+	for i := range txs {
+		txs[i].ID = uint(i + 1)
+	}
+
+	path := filepath.Join(t.TempDir(), "finances.journal")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := export.WriteLedger(file, categories, txs, export.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := ImportFile(context.Background(), path, JournalProfile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Imported != len(txs) || summary.Failed != 0 {
+		t.Fatalf("summary = %+v, want %d imported", summary, len(txs))
+	}
+
+	var imported []models.Transaction
+	if err := db.DB.Order("id").Find(&imported).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != len(txs) {
+		t.Fatalf("imported %d rows, want %d", len(imported), len(txs))
+	}
+	for i, got := range imported {
+		want := txs[i]
+		got.ID, got.BatchID = want.ID, nil
+		got.Date = got.Date.UTC()
+		if got.CategoryID != want.CategoryID || got.Amount != want.Amount || !got.Date.Equal(want.Date) ||
+			got.Payee != want.Payee || got.Description != want.Description || got.Account != want.Account ||
+			got.Currency != want.Currency || got.Tags != want.Tags || got.IsTransfer != want.IsTransfer ||
+			got.ImportID != want.ImportID || got.SplitGroup != want.SplitGroup {
+			t.Errorf("row %d = %+v\nwant %+v", i, got, want)
+		}
+	}
+
+	// importing the journal again changes nothing
+	summary, err = ImportFile(context.Background(), path, JournalProfile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Imported != 0 || summary.Skipped != len(txs) {
+		t.Errorf("second import = %+v, want every row skipped", summary)
+	}
+}
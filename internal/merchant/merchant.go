@@ -0,0 +1,91 @@
+// Package merchant normalizes raw bank descriptions into merchant names.
+package merchant
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/models"
+	"regexp"
+	"strings"
+)
+
+// Matcher finds the merchant of a raw description. It is safe for
+// concurrent use once built.
+type Matcher struct {
+	aliases []alias
+}
+
+type alias struct {
+	re       *regexp.Regexp
+	merchant *models.Merchant
+}
+
+// New compiles the aliases of the merchants. Merchants are tried in the
+// given order, their aliases in turn.
+func New(merchants []models.Merchant) (*Matcher, error) {
+	m := &Matcher{}
+	for i := range merchants {
+		merchant := &merchants[i]
+		for _, a := range merchant.Aliases {
+			re, err := Compile(a.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("merchant %s: %w", merchant.Name, err)
+			}
+			m.aliases = append(m.aliases, alias{re: re, merchant: merchant})
+		}
+	}
+	return m, nil
+}
+
+// Compile compiles an alias pattern; matching ignores case
+func Compile(pattern string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("alias pattern is empty")
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alias pattern %s: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// Match returns the merchant whose alias matches one of the texts
+func (m *Matcher) Match(texts ...string) (*models.Merchant, bool) {
+	for _, a := range m.aliases {
+		for _, text := range texts {
+			if text != "" && a.re.MatchString(text) {
+				return a.merchant, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Normalize sets the payee of a transaction to its merchant's name. The
+// raw text is kept as description when the transaction has none.
+func (m *Matcher) Normalize(tx *models.Transaction) (*models.Merchant, bool) {
+	merchant, ok := m.Match(tx.Payee, tx.Description)
+	if !ok {
+		tx.Payee = Clean(tx.Payee)
+		return nil, false
+	}
+	if tx.Description == "" {
+		tx.Description = tx.Payee
+	}
+	tx.Payee = merchant.Name
+	return merchant, true
+}
+
+// Clean collapses the runs of spaces banks pad descriptions with
+func Clean(payee string) string {
+	return strings.Join(strings.Fields(payee), " ")
+}
+
+// AliasFor returns an alias pattern matching a raw payee exactly, however
+// its words are spaced
+func AliasFor(payee string) string {
+	words := strings.Fields(payee)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return `^\s*` + strings.Join(words, `\s+`) + `\s*$`
+}
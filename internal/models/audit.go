@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// AuditEntry records a change made to many transactions at once, with
+// enough detail to see what each transaction was before
+type AuditEntry struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	// Action names the operation, e.g. "recategorize"
+	Action  string `gorm:"index"`
+	Summary string
+	Changes []AuditChange `gorm:"serializer:json"`
+}
+
+// AuditChange is one transaction changed by an audited operation
+type AuditChange struct {
+	TransactionID uint   `json:"transaction_id"`
+	Field         string `json:"field"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+}
+
+// CategoryMove is a transaction the rules would move to another category
+type CategoryMove struct {
+	Transaction Transaction
+	From        Category
+	To          Category
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// BudgetAlert records that the spending of a category reached one of its
+// alert thresholds in the budget period starting on PeriodStart, so the
+// alert is sent once per period. It is removed again when the spending
+// drops back below the threshold. A Projected alert warned that the
+// spending was on course to exceed the budget; it is sent once per period.
+type BudgetAlert struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	CategoryID  uint      `gorm:"not null;uniqueIndex:idx_budget_alert"`
+	PeriodStart time.Time `gorm:"not null;uniqueIndex:idx_budget_alert"`
+	Threshold   int       `gorm:"not null;uniqueIndex:idx_budget_alert"`
+	Projected   bool      `gorm:"not null;default:false;uniqueIndex:idx_budget_alert"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// BudgetAmount is the budget of a category from the budget period starting
+// on Start. End, when set, is the start of the first period it no longer
+// covers, so a change for a single period does not carry on. For a period
+// the entry with the latest Start covering it applies; without any, the
+// category's Budget does.
+type BudgetAmount struct {
+	ID         uint      `gorm:"primaryKey"`
+	CategoryID uint      `gorm:"not null;index"`
+	Start      time.Time `gorm:"not null"`
+	End        *time.Time
+	Amount     float32 `gorm:"not null"`
+}
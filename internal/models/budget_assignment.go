@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// BudgetAssignment funds a category from the to be assigned pool in
+// zero-based budgeting. It counts for the budget period of the category
+// holding Date; a negative amount takes money back to the pool.
+type BudgetAssignment struct {
+	ID         uint `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	CategoryID uint      `gorm:"not null;index"`
+	Date       time.Time `gorm:"not null;index"`
+	Amount     float32   `gorm:"not null"`
+}
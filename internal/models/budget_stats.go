@@ -1,7 +1,80 @@
 package models
 
+import (
+	"math"
+	"time"
+)
+
+// BudgetStats is the spending of a category within one budget period
 type BudgetStats struct {
 	CategoryName string
+	Kind         string
 	Budget       float64
 	Spent        float32
+
+	// Carried rolled over from the period before and Moved came in from
+	// other envelopes, negative when more went out
+	Carried  float32
+	Moved    float32
+	Rollover string
+
+	// Projected is the spending expected by the end of the period, set
+	// with HasProjection for the period under way
+	Projected     float32
+	HasProjection bool
+
+	// Percent is the share of income the budget is set to, 0 for a fixed
+	// amount; Income is the income of the period budgets are shares of
+	Percent float32
+	Group   string
+	Income  float32
+
+	Period      string // weekly, monthly, quarterly or yearly
+	PeriodLabel string
+	Start       time.Time
+	End         time.Time // first day after the period
+}
+
+// Available is what the envelope of the category holds for the period
+func (s BudgetStats) Available() float64 {
+	return s.Budget + float64(s.Carried) + float64(s.Moved)
+}
+
+// IncomeShare is the spending as a share of the period's income, in
+// percent; 0 without income
+func (s BudgetStats) IncomeShare() float64 {
+	if s.Income <= 0 {
+		return 0
+	}
+	return float64(s.Spent) / float64(s.Income) * 100
+}
+
+// GroupStats is the spending of a budget template group within a calendar
+// month against the share of income the template gives it
+type GroupStats struct {
+	Group   string
+	Share   float64
+	Minimum bool
+	Spent   float32
+	Income  float32
+	// Categories are the names of the categories in the group
+	Categories []string
+}
+
+// Percent is the spending of the group as a share of income, in percent.
+// Spending without income is infinitely over any share.
+func (g GroupStats) Percent() float64 {
+	switch {
+	case g.Spent <= 0:
+		return 0
+	case g.Income <= 0:
+		return math.Inf(1)
+	}
+	return float64(g.Spent) / float64(g.Income) * 100
+}
+
+// Over reports whether the group spent more than its share; a minimum
+// share is never over
+func (g GroupStats) Over() bool {
+	return !g.Minimum && g.Percent() > g.Share
 }
@@ -1,7 +1,54 @@
 package models
 
+import "time"
+
+// IncomeCategory receives money coming in
+const IncomeCategory = "Income"
+
+// Category kinds: spending is budgeted, income funds the budgets
+const (
+	KindExpense = "expense"
+	KindIncome  = "income"
+)
+
+// DefaultKind is the kind a new category gets from its name
+func DefaultKind(name string) string {
+	if name == IncomeCategory {
+		return KindIncome
+	}
+	return KindExpense
+}
+
 type Category struct {
 	ID     uint    `gorm:"primaryKey"`
 	Name   string  `gorm:"unique"`
 	Budget float32 `gorm:"not null;default:0"`
+	Kind   string  `gorm:"not null;default:expense"`
+
+	// BudgetPeriod is how often the budget starts over, one of the budget
+	// package periods. BudgetAnchor is the first day of one of its
+	// periods, zero to follow the calendar.
+	BudgetPeriod string `gorm:"not null;default:monthly"`
+	BudgetAnchor time.Time
+
+	// Rollover is what happens to money left at the end of a period, one
+	// of the budget package rollover modes. Balances roll over from the
+	// period holding RolloverFrom, when rollover was switched on.
+	Rollover     string `gorm:"not null;default:none"`
+	RolloverFrom time.Time
+
+	// AlertThresholds are the percentages of the budget that send an
+	// alert, each once per period; empty means the budget package defaults
+	AlertThresholds []int `gorm:"serializer:json"`
+
+	// BudgetPercent, when set, makes the budget of every period that share
+	// of the period's income, in percent, instead of the amount.
+	// BudgetGroup is the budget template group the category counts
+	// towards, such as needs, wants or savings.
+	BudgetPercent float32 `gorm:"not null;default:0"`
+	BudgetGroup   string  `gorm:"not null;default:''"`
+
+	// BatchID is set when the category was created by an import, so
+	// reverting that import can remove it again.
+	BatchID *uint
 }
@@ -1,8 +1,83 @@
 package models
 
-import "regexp"
-
+// CategoryRule is a rule of the rule engine. A rule matches when Pattern,
+// if set, matches the payee or description and its Conditions hold,
+// combined as Match says. Its actions are setting Category, if set,
+// followed by Actions. Rules are tried by ascending Priority; Stop ends
+// the evaluation after this rule matched.
 type CategoryRule struct {
-	Pattern  *regexp.Regexp
-	Category string
+	ID         uint `gorm:"primaryKey"`
+	Name       string
+	Pattern    string
+	CategoryID *uint `gorm:"index"`
+	Priority   int   `gorm:"not null;default:0;index"`
+	Enabled    bool  `gorm:"not null"`
+
+	Match      string          `gorm:"not null;default:all"`
+	Conditions []RuleCondition `gorm:"serializer:json"`
+	Actions    []RuleAction    `gorm:"serializer:json"`
+	Stop       bool            `gorm:"not null;default:false"`
+
+	Category *Category
+}
+
+// Rule condition fields
+const (
+	FieldPayee       = "payee"
+	FieldDescription = "description"
+	FieldText        = "text" // payee or description
+	FieldAmount      = "amount"
+	FieldSign        = "sign"
+	FieldAccount     = "account"
+	FieldDay         = "day" // day of month
+	FieldCurrency    = "currency"
+)
+
+// Rule condition operators
+const (
+	OpEquals   = "equals"
+	OpContains = "contains"
+	OpMatches  = "matches" // regular expression
+	OpBetween  = "between" // inclusive range "min..max", either side may be empty
+	OpIs       = "is"      // sign: positive or negative
+)
+
+// Rule match modes
+const (
+	MatchAll = "all"
+	MatchAny = "any"
+)
+
+// RuleCondition is one test on a transaction, or a group of conditions
+// when All or Any is set
+type RuleCondition struct {
+	Field string          `json:"field,omitempty" yaml:"field,omitempty"`
+	Op    string          `json:"op,omitempty" yaml:"op,omitempty"`
+	Value string          `json:"value,omitempty" yaml:"value,omitempty"`
+	Not   bool            `json:"not,omitempty" yaml:"not,omitempty"`
+	All   []RuleCondition `json:"all,omitempty" yaml:"all,omitempty"`
+	Any   []RuleCondition `json:"any,omitempty" yaml:"any,omitempty"`
+}
+
+// Rule action types
+const (
+	ActionCategory = "category"
+	ActionTag      = "tag"
+	ActionPayee    = "payee" // rename the payee
+	ActionTransfer = "transfer"
+	ActionSplit    = "split"
+	ActionSkip     = "skip" // leave the row out of an import
+)
+
+// RuleAction changes a matching transaction
+type RuleAction struct {
+	Type   string      `json:"type" yaml:"type"`
+	Value  string      `json:"value,omitempty" yaml:"value,omitempty"`
+	Splits []RuleSplit `json:"splits,omitempty" yaml:"splits,omitempty"`
+}
+
+// RuleSplit is the share of a split transaction going to one category
+type RuleSplit struct {
+	Category string  `json:"category" yaml:"category"`
+	Percent  float64 `json:"percent" yaml:"percent"`
 }
@@ -0,0 +1,14 @@
+package models
+
+// DuplicatePair is two transactions that look like the same movement.
+type DuplicatePair struct {
+	First  Transaction
+	Second Transaction
+}
+
+// DuplicateDismissal records a pair the user reviewed and kept.
+type DuplicateDismissal struct {
+	ID       uint `gorm:"primaryKey"`
+	FirstID  uint `gorm:"uniqueIndex:idx_duplicate_pair"`
+	SecondID uint `gorm:"uniqueIndex:idx_duplicate_pair"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// EnvelopeTransfer moves budget money from one category to another for
+// the budget period holding Date
+type EnvelopeTransfer struct {
+	ID             uint `gorm:"primaryKey"`
+	CreatedAt      time.Time
+	Date           time.Time `gorm:"not null;index"`
+	FromCategoryID uint      `gorm:"not null;index"`
+	ToCategoryID   uint      `gorm:"not null;index"`
+	Amount         float32   `gorm:"not null"`
+	Reason         string    `gorm:"not null"`
+
+	FromCategory Category
+	ToCategory   Category
+}
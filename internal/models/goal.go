@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Goal is money to save by a date, such as a car or an emergency fund.
+// Besides its own contributions, a goal linked to an account or a category
+// counts their transactions from StartDate on as saved.
+type Goal struct {
+	ID         uint `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	Name       string    `gorm:"unique;not null"`
+	Target     float32   `gorm:"not null"`
+	StartDate  time.Time `gorm:"not null"`
+	TargetDate time.Time `gorm:"not null"`
+
+	Account    string `gorm:"not null;default:''"`
+	CategoryID *uint
+	Category   *Category
+
+	// Milestone is the highest milestone, in percent of the target, an
+	// alert was sent for
+	Milestone int `gorm:"not null;default:0"`
+
+	Contributions []GoalContribution
+}
+
+// GoalContribution is money put aside for a goal
+type GoalContribution struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	GoalID    uint      `gorm:"not null;index"`
+	Date      time.Time `gorm:"not null"`
+	Amount    float32   `gorm:"not null"`
+	Note      string
+}
+
+// GoalStatus is a goal with what was saved for it so far
+type GoalStatus struct {
+	Goal  Goal
+	Saved float32
+
+	// Required is the monthly contribution that still reaches the target
+	// by its date, Expected what a steady pace would have saved by now
+	Required float32
+	Expected float32
+	// MonthsLeft until the target date, counting the current month
+	MonthsLeft int
+	OnTrack    bool
+}
+
+// Progress is the share of the target saved, in percent
+func (s GoalStatus) Progress() float32 {
+	if s.Goal.Target <= 0 {
+		return 0
+	}
+	return s.Saved / s.Goal.Target * 100
+}
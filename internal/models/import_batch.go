@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ImportBatch is one import of a statement file. All transactions and
+// categories it created point back to it so it can be reverted as a whole.
+type ImportBatch struct {
+	ID         uint `gorm:"primaryKey"`
+	FileName   string
+	FileHash   string
+	Profile    string
+	Imported   int
+	Skipped    int
+	Failed     int
+	CreatedAt  time.Time
+	RevertedAt *time.Time
+}
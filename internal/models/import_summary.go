@@ -0,0 +1,10 @@
+package models
+
+// ImportSummary counts what happened to the rows of an import.
+type ImportSummary struct {
+	BatchID  uint
+	Imported int
+	Skipped  int
+	Failed   int
+	Errors   []string
+}
@@ -0,0 +1,27 @@
+package models
+
+// Merchant is a clean payee name. Raw bank descriptions matching one of
+// its aliases are stored under Name; CategoryID, when set, is the default
+// category of its transactions.
+type Merchant struct {
+	ID         uint   `gorm:"primaryKey"`
+	Name       string `gorm:"unique;not null"`
+	CategoryID *uint  `gorm:"index"`
+
+	Category *Category
+	Aliases  []MerchantAlias `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// MerchantAlias is a case-insensitive regular expression matched against
+// the raw payee or description
+type MerchantAlias struct {
+	ID         uint   `gorm:"primaryKey"`
+	MerchantID uint   `gorm:"index;not null"`
+	Pattern    string `gorm:"not null"`
+}
+
+// PayeeCount is a payee as stored on transactions and how often it occurs
+type PayeeCount struct {
+	Payee string
+	Count int
+}
@@ -0,0 +1,7 @@
+package models
+
+// Setting is a ledger-wide option stored by key
+type Setting struct {
+	Key   string `gorm:"primaryKey"`
+	Value string `gorm:"not null"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// Snapshot is the whole ledger as it is dumped to or restored from an
+// archive. References between its records use the IDs inside the snapshot.
+type Snapshot struct {
+	Categories          []Category
+	ImportBatches       []ImportBatch
+	Transactions        []Transaction
+	DuplicateDismissals []DuplicateDismissal
+	CategoryRules       []CategoryRule
+	Merchants           []Merchant
+	BudgetAmounts       []BudgetAmount
+	EnvelopeTransfers   []EnvelopeTransfer
+	BudgetAssignments   []BudgetAssignment
+	Settings            []Setting
+	Goals               []Goal
+}
@@ -0,0 +1,30 @@
+package models
+
+import "strings"
+
+// SplitTags returns the tags of a comma separated list, trimmed and
+// without empty entries
+func SplitTags(tags string) []string {
+	var list []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			list = append(list, tag)
+		}
+	}
+	return list
+}
+
+// JoinTags returns tags as comma separated list, leaving out duplicates
+func JoinTags(tags []string) string {
+	seen := make(map[string]bool, len(tags))
+	list := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		list = append(list, tag)
+	}
+	return strings.Join(list, ",")
+}
@@ -3,10 +3,28 @@ package models
 import "time"
 
 type Transaction struct {
-	ID         uint `gorm:"primaryKey"`
-	CategoryID uint
-	Amount     float32
-	Date       time.Time `gorm:"type:date"`
+	ID          uint `gorm:"primaryKey"`
+	CategoryID  uint
+	Amount      float32
+	Date        time.Time `gorm:"type:date"`
+	Payee       string
+	Description string
+	Account     string
+	Currency    string
+	// Tags is a comma separated list, see SplitTags and JoinTags
+	Tags string
+	// IsTransfer marks money moved between own accounts, which is neither
+	// income nor expense
+	IsTransfer bool
+
+	// ImportID identifies an imported row across re-imports: the bank
+	// reference when the statement has one, otherwise a fingerprint.
+	ImportID string `gorm:"index"`
+	// BatchID is the import batch the transaction came from, nil when added by hand
+	BatchID *uint `gorm:"index"`
+	// SplitGroup is shared by the parts of a transaction a rule split
+	// across several categories; empty for whole transactions
+	SplitGroup string `gorm:"index"`
 
 	Category Category `gorm:"foreignKey:CategoryID"`
 }
@@ -0,0 +1,437 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"peronal_finance_cli_manager/internal/models"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Engine evaluates compiled rules against transactions. It is safe for
+// concurrent use once built.
+type Engine struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	rule     models.CategoryRule
+	category string
+	pattern  *regexp.Regexp
+	match    func(tx models.Transaction) bool
+}
+
+// Result is what the rules did to a transaction
+type Result struct {
+	// Transaction is the transaction with every action applied. Its
+	// Category.Name is only set when a rule assigned one.
+	Transaction models.Transaction
+	// Splits are the parts of the transaction when a rule split it; their
+	// amounts add up to the transaction amount
+	Splits []models.Transaction
+	// Skip is set when a rule asked to leave the row out of an import
+	Skip bool
+	// Matched are the ids of the rules that matched, in order
+	Matched []uint
+}
+
+// New compiles rules, which must be in priority order. Disabled rules are
+// left out; a rule that does not compile is an error naming it.
+func New(rules []models.CategoryRule) (*Engine, error) {
+	e := &Engine{}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, compiled)
+	}
+	return e, nil
+}
+
+// Check reports what is wrong with a rule, if anything
+func Check(rule models.CategoryRule) error {
+	_, err := compile(rule)
+	return err
+}
+
+// compile checks a rule and prepares its conditions
+func compile(rule models.CategoryRule) (compiledRule, error) {
+	name := rule.Name
+	if name == "" {
+		name = fmt.Sprintf("#%d", rule.ID)
+	}
+	fail := func(err error) (compiledRule, error) {
+		return compiledRule{}, fmt.Errorf("rule %s: %w", name, err)
+	}
+
+	var group models.RuleCondition
+	switch rule.Match {
+	case models.MatchAll, "":
+		group.All = rule.Conditions
+	case models.MatchAny:
+		group.Any = rule.Conditions
+	default:
+		return fail(fmt.Errorf("unknown match mode '%s'", rule.Match))
+	}
+	if rule.Pattern != "" {
+		// the pattern has to hold whatever the match mode
+		group = models.RuleCondition{All: []models.RuleCondition{
+			{Field: models.FieldText, Op: models.OpMatches, Value: rule.Pattern},
+			group,
+		}}
+	}
+
+	match, err := compileCondition(group)
+	if err != nil {
+		return fail(err)
+	}
+	for _, action := range rule.Actions {
+		if err := checkAction(action); err != nil {
+			return fail(err)
+		}
+	}
+
+	compiled := compiledRule{rule: rule, match: match}
+	if rule.Category != nil {
+		compiled.category = rule.Category.Name
+	}
+	if rule.Pattern != "" {
+		// it compiled as a condition above
+		compiled.pattern = regexp.MustCompile(rule.Pattern)
+	}
+	return compiled, nil
+}
+
+func compileCondition(c models.RuleCondition) (func(models.Transaction) bool, error) {
+	match, err := compileTest(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.Not {
+		return func(tx models.Transaction) bool { return !match(tx) }, nil
+	}
+	return match, nil
+}
+
+func compileTest(c models.RuleCondition) (func(models.Transaction) bool, error) {
+	if len(c.All) > 0 || len(c.Any) > 0 || c.Field == "" {
+		all, err := compileList(c.All)
+		if err != nil {
+			return nil, err
+		}
+		anyOf, err := compileList(c.Any)
+		if err != nil {
+			return nil, err
+		}
+		return func(tx models.Transaction) bool {
+			for _, m := range all {
+				if !m(tx) {
+					return false
+				}
+			}
+			if len(anyOf) == 0 {
+				return true
+			}
+			for _, m := range anyOf {
+				if m(tx) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	switch c.Field {
+	case models.FieldPayee, models.FieldDescription, models.FieldText,
+		models.FieldAccount, models.FieldCurrency:
+		test, err := textTest(c.Op, c.Value)
+		if err != nil {
+			return nil, err
+		}
+		field := c.Field
+		return func(tx models.Transaction) bool {
+			if field == models.FieldText {
+				return test(tx.Payee) || test(tx.Description)
+			}
+			return test(textField(tx, field))
+		}, nil
+
+	case models.FieldAmount, models.FieldDay:
+		test, err := numberTest(c.Op, c.Value)
+		if err != nil {
+			return nil, err
+		}
+		if c.Field == models.FieldDay {
+			return func(tx models.Transaction) bool { return test(float64(tx.Date.Day())) }, nil
+		}
+		return func(tx models.Transaction) bool { return test(float64(tx.Amount)) }, nil
+
+	case models.FieldSign:
+		if c.Op != models.OpIs && c.Op != models.OpEquals {
+			return nil, fmt.Errorf("sign only supports '%s'", models.OpIs)
+		}
+		switch strings.ToLower(c.Value) {
+		case "positive", "+":
+			return func(tx models.Transaction) bool { return tx.Amount > 0 }, nil
+		case "negative", "-":
+			return func(tx models.Transaction) bool { return tx.Amount < 0 }, nil
+		}
+		return nil, fmt.Errorf("sign must be positive or negative, got '%s'", c.Value)
+	}
+
+	return nil, fmt.Errorf("unknown field '%s'", c.Field)
+}
+
+func compileList(conditions []models.RuleCondition) ([]func(models.Transaction) bool, error) {
+	list := make([]func(models.Transaction) bool, 0, len(conditions))
+	for _, c := range conditions {
+		m, err := compileCondition(c)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+func textField(tx models.Transaction, field string) string {
+	switch field {
+	case models.FieldPayee:
+		return tx.Payee
+	case models.FieldDescription:
+		return tx.Description
+	case models.FieldAccount:
+		return tx.Account
+	case models.FieldCurrency:
+		return tx.Currency
+	}
+	return ""
+}
+
+// textTest compares text case-insensitively, or with a regular expression
+func textTest(op, value string) (func(string) bool, error) {
+	switch op {
+	case models.OpEquals:
+		return func(s string) bool { return strings.EqualFold(strings.TrimSpace(s), value) }, nil
+	case models.OpContains:
+		lower := strings.ToLower(value)
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), lower) }, nil
+	case models.OpMatches:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		return re.MatchString, nil
+	}
+	return nil, fmt.Errorf("text fields support %s, %s and %s, got '%s'",
+		models.OpEquals, models.OpContains, models.OpMatches, op)
+}
+
+func numberTest(op, value string) (func(float64) bool, error) {
+	switch op {
+	case models.OpEquals:
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s'", value)
+		}
+		return func(f float64) bool { return math.Abs(f-n) < 0.005 }, nil
+
+	case models.OpBetween:
+		low, high, err := ParseRange(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(f float64) bool { return f >= low-0.005 && f <= high+0.005 }, nil
+	}
+	return nil, fmt.Errorf("number fields support %s and %s, got '%s'", models.OpEquals, models.OpBetween, op)
+}
+
+// ParseRange reads "min..max"; an empty side is unbounded
+func ParseRange(value string) (float64, float64, error) {
+	lowStr, highStr, ok := strings.Cut(value, "..")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid range '%s', use min..max", value)
+	}
+	low, high := math.Inf(-1), math.Inf(1)
+	var err error
+	if s := strings.TrimSpace(lowStr); s != "" {
+		if low, err = strconv.ParseFloat(s, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid range '%s'", value)
+		}
+	}
+	if s := strings.TrimSpace(highStr); s != "" {
+		if high, err = strconv.ParseFloat(s, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid range '%s'", value)
+		}
+	}
+	return low, high, nil
+}
+
+func checkAction(a models.RuleAction) error {
+	switch a.Type {
+	case models.ActionCategory, models.ActionTag, models.ActionPayee:
+		if strings.TrimSpace(a.Value) == "" {
+			return fmt.Errorf("%s action needs a value", a.Type)
+		}
+	case models.ActionTransfer, models.ActionSkip:
+	case models.ActionSplit:
+		if len(a.Splits) < 2 {
+			return fmt.Errorf("split action needs at least two parts")
+		}
+		total := 0.0
+		for _, s := range a.Splits {
+			if s.Category == "" || s.Percent <= 0 {
+				return fmt.Errorf("split parts need a category and a positive percentage")
+			}
+			total += s.Percent
+		}
+		if math.Abs(total-100) > 0.001 {
+			return fmt.Errorf("split percentages add up to %g, not 100", total)
+		}
+	default:
+		return fmt.Errorf("unknown action '%s'", a.Type)
+	}
+	return nil
+}
+
+// Apply runs every matching rule in order until one with Stop matched.
+// The first rule to set the category, payee or split wins; tags add up.
+func (e *Engine) Apply(tx models.Transaction) Result {
+	d := newDecisions(tx)
+	for _, r := range e.rules {
+		// conditions see the transaction as it came in
+		if !r.match(tx) {
+			continue
+		}
+		d.apply(r)
+		if r.rule.Stop {
+			break
+		}
+	}
+	return d.result()
+}
+
+// decisions gathers the actions of the matching rules. The first rule to
+// set a field decides it, and owner remembers which rule that was.
+type decisions struct {
+	res      Result
+	category string
+	payee    string
+	splits   []models.RuleSplit
+	tags     []string
+	owner    map[string]uint
+}
+
+func newDecisions(tx models.Transaction) *decisions {
+	d := &decisions{
+		res:   Result{Transaction: tx},
+		tags:  models.SplitTags(tx.Tags),
+		owner: make(map[string]uint),
+	}
+	d.res.Transaction.Category = models.Category{}
+	for _, tag := range d.tags {
+		d.owner["tag:"+tag] = 0
+	}
+	if tx.IsTransfer {
+		d.owner[models.ActionTransfer] = 0
+	}
+	if tx.SplitGroup != "" {
+		// a part of a split is not split again
+		d.owner[models.ActionSplit] = 0
+	}
+	return d
+}
+
+// apply records the actions of a matching rule. It returns the actions
+// that took effect and the rules that had already decided the others; an
+// action already true of the transaction itself counts as decided too.
+func (d *decisions) apply(r compiledRule) (effects []string, decidedBy []uint) {
+	d.res.Matched = append(d.res.Matched, r.rule.ID)
+
+	decide := func(key, effect string, set func()) {
+		if by, ok := d.owner[key]; ok {
+			if by != 0 && by != r.rule.ID && !slices.Contains(decidedBy, by) {
+				decidedBy = append(decidedBy, by)
+			}
+			return
+		}
+		d.owner[key] = r.rule.ID
+		set()
+		effects = append(effects, effect)
+	}
+
+	if r.category != "" {
+		decide(models.ActionCategory, "category "+r.category, func() { d.category = r.category })
+	}
+	for _, a := range r.rule.Actions {
+		effect := FormatActions([]models.RuleAction{a})
+		switch a.Type {
+		case models.ActionCategory:
+			decide(models.ActionCategory, effect, func() { d.category = a.Value })
+		case models.ActionTag:
+			decide("tag:"+a.Value, effect, func() { d.tags = append(d.tags, a.Value) })
+		case models.ActionPayee:
+			decide(models.ActionPayee, effect, func() { d.payee = a.Value })
+		case models.ActionTransfer:
+			decide(models.ActionTransfer, effect, func() { d.res.Transaction.IsTransfer = true })
+		case models.ActionSplit:
+			decide(models.ActionSplit, effect, func() { d.splits = a.Splits })
+		case models.ActionSkip:
+			decide(models.ActionSkip, effect, func() { d.res.Skip = true })
+		}
+	}
+	return effects, decidedBy
+}
+
+// result is the transaction with every recorded action applied
+func (d *decisions) result() Result {
+	res := d.res
+	res.Transaction.Category.Name = d.category
+	if d.payee != "" {
+		res.Transaction.Payee = d.payee
+	}
+	res.Transaction.Tags = models.JoinTags(d.tags)
+	if d.splits != nil {
+		res.Splits = split(res.Transaction, d.splits)
+	}
+	return res
+}
+
+// split divides a transaction by percentage. Amounts are rounded to cents
+// and the last part takes the rounding difference. The parts share the
+// transaction's SplitGroup, or its import identity when it has none; the
+// first part keeps the import identity and the others get "/2", "/3", ...
+// A transaction that already has a SplitGroup is a part of a split and is
+// not split again: there are no parts.
+func split(tx models.Transaction, parts []models.RuleSplit) []models.Transaction {
+	if tx.SplitGroup != "" {
+		return nil
+	}
+	tx.SplitGroup = tx.ImportID
+
+	result := make([]models.Transaction, 0, len(parts))
+	rest := float64(tx.Amount)
+	for i, p := range parts {
+		part := tx
+		part.ID = 0
+		part.Category = models.Category{Name: p.Category}
+		part.CategoryID = 0
+		if i > 0 && tx.ImportID != "" {
+			part.ImportID = fmt.Sprintf("%s/%d", tx.ImportID, i+1)
+		}
+		amount := math.Round(float64(tx.Amount)*p.Percent) / 100
+		if i == len(parts)-1 {
+			amount = rest
+		}
+		rest -= amount
+		part.Amount = float32(amount)
+		result = append(result, part)
+	}
+	return result
+}
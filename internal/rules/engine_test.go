@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"math"
+	"peronal_finance_cli_manager/internal/models"
+	"testing"
+)
+
+func part(category string, percent float64) models.RuleSplit {
+	return models.RuleSplit{Category: category, Percent: percent}
+}
+
+func TestSplit(t *testing.T) {
+	third := 100.0 / 3
+	tests := []struct {
+		name   string
+		amount float32
+		parts  []models.RuleSplit
+		want   []float32
+	}{
+		{"even halves", 100, []models.RuleSplit{part("Food", 50), part("Household", 50)}, []float32{50, 50}},
+		{"thirds leave the remainder to the last part", 100,
+			[]models.RuleSplit{part("Food", third), part("Household", third), part("Leisure", third)}, []float32{33.33, 33.33, 33.34}},
+		{"odd cent", 10.01, []models.RuleSplit{part("Food", 50), part("Household", 50)}, []float32{5.01, 5}},
+		{"negative amount", -99.99, []models.RuleSplit{part("Food", 70), part("Household", 30)}, []float32{-69.99, -30}},
+		{"single cent", 0.01, []models.RuleSplit{part("Food", 50), part("Household", 50)}, []float32{0, 0.01}},
+		{"uneven percentages", 1234.56, []models.RuleSplit{part("Food", 12.5), part("Household", 62.5), part("Leisure", 25)},
+			[]float32{154.32, 771.6, 308.64}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := models.Transaction{Amount: tt.amount, Payee: "Shop", ImportID: "sha256:abc"}
+			got := split(tx, tt.parts)
+			if len(got) != len(tt.want) {
+				t.Fatalf("split made %d parts, want %d", len(got), len(tt.want))
+			}
+
+			var sum float64
+			for i, part := range got {
+				sum += float64(part.Amount)
+				if math.Abs(float64(part.Amount-tt.want[i])) > 0.001 {
+					t.Errorf("part %d = %.2f, want %.2f", i+1, part.Amount, tt.want[i])
+				}
+				if part.Category.Name != tt.parts[i].Category {
+					t.Errorf("part %d category = %s, want %s", i+1, part.Category.Name, tt.parts[i].Category)
+				}
+				if part.SplitGroup != "sha256:abc" {
+					t.Errorf("part %d split group = %s, want the import id", i+1, part.SplitGroup)
+				}
+			}
+			if math.Abs(sum-float64(tt.amount)) > 0.001 {
+				t.Errorf("parts add up to %.4f, want %.2f", sum, tt.amount)
+			}
+			if got[0].ImportID != "sha256:abc" || (len(got) > 1 && got[1].ImportID != "sha256:abc/2") {
+				t.Errorf("import ids = %s, %s; want the first part to keep the id and the next to get /2",
+					got[0].ImportID, got[len(got)-1].ImportID)
+			}
+		})
+	}
+}
+
+func TestApplyDoesNotSplitParts(t *testing.T) {
+	engine, err := New([]models.CategoryRule{{
+		ID:         1,
+		Enabled:    true,
+		Conditions: []models.RuleCondition{{Field: models.FieldPayee, Op: models.OpContains, Value: "market"}},
+		Actions:    []models.RuleAction{{Type: models.ActionSplit, Splits: []models.RuleSplit{part("Food", 50), part("Home", 50)}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		group string
+		parts int
+	}{
+		{"whole row is split", "", 2},
+		{"part of a split stays whole", "abc", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := models.Transaction{Payee: "Market", Amount: 40, ImportID: "abc", SplitGroup: tt.group}
+			if got := engine.Apply(tx).Splits; len(got) != tt.parts {
+				t.Errorf("Apply made %d parts, want %d", len(got), tt.parts)
+			}
+			if got := split(tx, []models.RuleSplit{part("Food", 50), part("Home", 50)}); len(got) != tt.parts {
+				t.Errorf("split made %d parts, want %d", len(got), tt.parts)
+			}
+		})
+	}
+}
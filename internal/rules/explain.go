@@ -0,0 +1,158 @@
+package rules
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/models"
+	"slices"
+)
+
+// Step is how one rule fared against a transaction
+type Step struct {
+	Rule models.CategoryRule
+	// Err is set when the rule does not compile; it was not evaluated
+	Err error
+	// Matched is set when the conditions hold, for disabled rules too
+	Matched bool
+	// StoppedBy is the rule with Stop that ended the evaluation before
+	// this one, 0 when the rule was reached
+	StoppedBy uint
+	// Groups are the submatches of the pattern, "name=value" for named
+	// groups and "$1=value" for the others
+	Groups []string
+	// Effects are the actions that changed the result
+	Effects []string
+	// DecidedBy are the earlier rules that had already set what the other
+	// actions of this rule would have set
+	DecidedBy []uint
+}
+
+// Applied reports whether the rule took part in the result
+func (s Step) Applied() bool {
+	return s.Err == nil && s.Matched && s.Rule.Enabled && s.StoppedBy == 0
+}
+
+// Explain evaluates every rule of list, which must be in priority order,
+// against a transaction and tells what each did. Unlike an engine it keeps
+// disabled and broken rules, so the list reads like the rules screen.
+func Explain(list []models.CategoryRule, tx models.Transaction) ([]Step, Result) {
+	d := newDecisions(tx)
+	var stoppedBy uint
+	steps := make([]Step, 0, len(list))
+
+	for _, rule := range list {
+		step := Step{Rule: rule, StoppedBy: stoppedBy}
+		r, err := compile(rule)
+		if err != nil {
+			step.Err = err
+			steps = append(steps, step)
+			continue
+		}
+
+		step.Matched = r.match(tx)
+		if step.Matched {
+			step.Groups = groups(r, tx)
+		}
+		if step.Applied() {
+			step.Effects, step.DecidedBy = d.apply(r)
+			if rule.Stop {
+				stoppedBy = rule.ID
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, d.result()
+}
+
+// groups returns the submatches of the rule pattern on the payee, or on
+// the description when the payee does not match
+func groups(r compiledRule, tx models.Transaction) []string {
+	if r.pattern == nil {
+		return nil
+	}
+	match := r.pattern.FindStringSubmatch(tx.Payee)
+	if match == nil {
+		match = r.pattern.FindStringSubmatch(tx.Description)
+	}
+
+	var list []string
+	for i, name := range r.pattern.SubexpNames() {
+		if i == 0 || i >= len(match) {
+			continue
+		}
+		if name == "" {
+			name = fmt.Sprintf("$%d", i)
+		}
+		list = append(list, name+"="+match[i])
+	}
+	return list
+}
+
+// Coverage is how an enabled rule fared against a set of transactions
+type Coverage struct {
+	Rule models.CategoryRule
+	// Matched counts the transactions its conditions match
+	Matched int
+	// Effective counts the transactions it changed
+	Effective int
+	// ShadowedBy are the higher-priority rules that stopped the evaluation
+	// or had already decided every action where this rule matched to no
+	// effect
+	ShadowedBy []uint
+}
+
+// Never reports a rule that matches none of the transactions
+func (c Coverage) Never() bool {
+	return c.Matched == 0
+}
+
+// Shadowed reports a rule that matches transactions but never changes any
+// of them, because higher-priority rules always decide first
+func (c Coverage) Shadowed() bool {
+	return c.Matched > 0 && c.Effective == 0
+}
+
+// Analyze evaluates the enabled rules of list, in priority order, against
+// every transaction. Tags and the transfer flag are cleared first since
+// stored transactions carry them from the rules being analyzed. Rules that
+// do not compile are left out.
+func Analyze(list []models.CategoryRule, txs []models.Transaction) []Coverage {
+	var enabled []models.CategoryRule
+	var coverage []Coverage
+	for _, rule := range list {
+		if rule.Enabled && Check(rule) == nil {
+			enabled = append(enabled, rule)
+			coverage = append(coverage, Coverage{Rule: rule})
+		}
+	}
+
+	for _, tx := range txs {
+		tx.Tags = ""
+		tx.IsTransfer = false
+		steps, _ := Explain(enabled, tx)
+		for i, step := range steps {
+			c := &coverage[i]
+			if !step.Matched {
+				continue
+			}
+			c.Matched++
+			switch {
+			case len(step.Effects) > 0:
+				c.Effective++
+			case step.StoppedBy != 0:
+				c.ShadowedBy = addID(c.ShadowedBy, step.StoppedBy)
+			default:
+				for _, id := range step.DecidedBy {
+					c.ShadowedBy = addID(c.ShadowedBy, id)
+				}
+			}
+		}
+	}
+	return coverage
+}
+
+func addID(ids []uint, id uint) []uint {
+	if slices.Contains(ids, id) {
+		return ids
+	}
+	return append(ids, id)
+}
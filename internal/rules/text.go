@@ -0,0 +1,193 @@
+package rules
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/models"
+	"strconv"
+	"strings"
+)
+
+// The text form of conditions and actions is what the rules screen edits.
+//
+// Conditions are separated by ";" and read "[not] FIELD OP VALUE", e.g.
+//
+//	payee contains uber; amount between 10..50; not account equals Savings
+//
+// A group is written all(...) or any(...) with its conditions separated by
+// ",". Actions are separated by ";" as well:
+//
+//	category Transport; tag travel; payee Uber; transfer; skip
+//	split Food=60,Household=40
+
+// ParseConditions reads conditions in their text form
+func ParseConditions(text string) ([]models.RuleCondition, error) {
+	var conditions []models.RuleCondition
+	for _, part := range splitTop(text, ';') {
+		c, err := parseCondition(part)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+func parseCondition(text string) (models.RuleCondition, error) {
+	var c models.RuleCondition
+	if rest, ok := cutWord(text, "not"); ok {
+		c.Not = true
+		text = rest
+	}
+
+	for _, mode := range []string{models.MatchAll, models.MatchAny} {
+		inner, ok := strings.CutPrefix(text, mode+"(")
+		if !ok {
+			continue
+		}
+		inner, ok = strings.CutSuffix(inner, ")")
+		if !ok {
+			return c, fmt.Errorf("missing ) in '%s'", text)
+		}
+		var list []models.RuleCondition
+		for _, part := range splitTop(inner, ',') {
+			sub, err := parseCondition(part)
+			if err != nil {
+				return c, err
+			}
+			list = append(list, sub)
+		}
+		if mode == models.MatchAll {
+			c.All = list
+		} else {
+			c.Any = list
+		}
+		return c, nil
+	}
+
+	field, rest, _ := strings.Cut(text, " ")
+	op, value, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	c.Field = strings.ToLower(field)
+	c.Op = strings.ToLower(op)
+	c.Value = strings.TrimSpace(value)
+	if c.Op == "" || c.Value == "" {
+		return c, fmt.Errorf("condition '%s' needs a field, an operator and a value", text)
+	}
+
+	if _, err := compileCondition(c); err != nil {
+		return c, fmt.Errorf("condition '%s': %w", text, err)
+	}
+	return c, nil
+}
+
+// FormatConditions writes conditions in their text form
+func FormatConditions(conditions []models.RuleCondition) string {
+	parts := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		parts = append(parts, formatCondition(c))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatCondition(c models.RuleCondition) string {
+	prefix := ""
+	if c.Not {
+		prefix = "not "
+	}
+
+	group := func(mode string, list []models.RuleCondition) string {
+		parts := make([]string, 0, len(list))
+		for _, sub := range list {
+			parts = append(parts, formatCondition(sub))
+		}
+		return mode + "(" + strings.Join(parts, ", ") + ")"
+	}
+	switch {
+	case len(c.All) > 0 && len(c.Any) > 0:
+		return prefix + group(models.MatchAll, append(append([]models.RuleCondition{}, c.All...),
+			models.RuleCondition{Any: c.Any}))
+	case len(c.All) > 0:
+		return prefix + group(models.MatchAll, c.All)
+	case len(c.Any) > 0:
+		return prefix + group(models.MatchAny, c.Any)
+	}
+	return fmt.Sprintf("%s%s %s %s", prefix, c.Field, c.Op, c.Value)
+}
+
+// ParseActions reads actions in their text form
+func ParseActions(text string) ([]models.RuleAction, error) {
+	var actions []models.RuleAction
+	for _, part := range splitTop(text, ';') {
+		kind, value, _ := strings.Cut(part, " ")
+		a := models.RuleAction{Type: strings.ToLower(kind), Value: strings.TrimSpace(value)}
+
+		if a.Type == models.ActionSplit {
+			for _, share := range strings.Split(a.Value, ",") {
+				category, percent, ok := strings.Cut(share, "=")
+				p, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(percent, "%")), 64)
+				if !ok || err != nil {
+					return nil, fmt.Errorf("split part '%s' must be Category=percent", strings.TrimSpace(share))
+				}
+				a.Splits = append(a.Splits, models.RuleSplit{Category: strings.TrimSpace(category), Percent: p})
+			}
+			a.Value = ""
+		}
+
+		if err := checkAction(a); err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// FormatActions writes actions in their text form
+func FormatActions(actions []models.RuleAction) string {
+	parts := make([]string, 0, len(actions))
+	for _, a := range actions {
+		switch {
+		case a.Type == models.ActionSplit:
+			shares := make([]string, 0, len(a.Splits))
+			for _, s := range a.Splits {
+				shares = append(shares, fmt.Sprintf("%s=%g", s.Category, s.Percent))
+			}
+			parts = append(parts, a.Type+" "+strings.Join(shares, ","))
+		case a.Value != "":
+			parts = append(parts, a.Type+" "+a.Value)
+		default:
+			parts = append(parts, a.Type)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// splitTop splits text at sep outside of parentheses and drops empty parts
+func splitTop(text string, sep rune) []string {
+	var parts []string
+	depth, start := 0, 0
+	add := func(end int) {
+		if part := strings.TrimSpace(text[start:end]); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	for i, r := range text {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				add(i)
+				start = i + 1
+			}
+		}
+	}
+	add(len(text))
+	return parts
+}
+
+// cutWord removes a leading word followed by a space
+func cutWord(text, word string) (string, bool) {
+	rest, ok := strings.CutPrefix(text, word+" ")
+	return strings.TrimSpace(rest), ok
+}
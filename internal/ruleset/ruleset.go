@@ -0,0 +1,296 @@
+package ruleset
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"peronal_finance_cli_manager/internal/rules"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the rule set format written by Export. Import reads rule sets
+// up to this version.
+const Version = 1
+
+// File formats
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// RuleSet is a shareable list of categorization rules. Rules refer to
+// categories by name, so a set can move between ledgers.
+type RuleSet struct {
+	Version int    `json:"version" yaml:"version"`
+	Rules   []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule is a categorization rule; rules are listed in priority order and a
+// rule without a priority keeps its place in the list
+type Rule struct {
+	Name       string                 `json:"name,omitempty" yaml:"name,omitempty"`
+	Priority   int                    `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Disabled   bool                   `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	Pattern    string                 `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Match      string                 `json:"match,omitempty" yaml:"match,omitempty"`
+	Conditions []models.RuleCondition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	Category   string                 `json:"category,omitempty" yaml:"category,omitempty"`
+	Actions    []models.RuleAction    `json:"actions,omitempty" yaml:"actions,omitempty"`
+	Stop       bool                   `json:"stop,omitempty" yaml:"stop,omitempty"`
+}
+
+// ValidationError lists every rule that stopped an import
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("rule set not imported, %d problem(s):\n  %s",
+		len(e.Problems), strings.Join(e.Problems, "\n  "))
+}
+
+// FormatOf picks the format from the file extension; anything but .json
+// is read as YAML
+func FormatOf(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// Export writes every rule of the ledger to a rule set file
+func Export(path string) (*RuleSet, error) {
+	list, err := db.GetCategoryRules()
+	if err != nil {
+		return nil, err
+	}
+	set := FromRules(list)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := set.Write(file, FormatOf(path)); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return set, file.Close()
+}
+
+// Import loads a rule set file into the ledger. With replace the current
+// rules are deleted first; otherwise a rule with the name of an existing
+// rule updates it and the others are added after the existing rules. Every
+// rule is checked first, and any problem is reported in a
+// *ValidationError and nothing is imported.
+func Import(path string, replace bool) (added, updated int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	set, err := Read(file, FormatOf(path))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	categories, err := db.GetAllCategories()
+	if err != nil {
+		return 0, 0, err
+	}
+	ids := make(map[string]uint, len(categories))
+	for _, c := range categories {
+		ids[c.Name] = c.ID
+	}
+
+	list, problems := set.ToRules(ids)
+	if len(problems) > 0 {
+		return 0, 0, &ValidationError{Problems: problems}
+	}
+	return db.ImportCategoryRules(list, replace)
+}
+
+// FromRules turns the rules of the ledger into a rule set
+func FromRules(list []models.CategoryRule) *RuleSet {
+	set := &RuleSet{Version: Version, Rules: make([]Rule, 0, len(list))}
+	for _, r := range list {
+		rule := Rule{
+			Name:       r.Name,
+			Priority:   r.Priority,
+			Disabled:   !r.Enabled,
+			Pattern:    r.Pattern,
+			Conditions: r.Conditions,
+			Actions:    r.Actions,
+			Stop:       r.Stop,
+		}
+		if r.Match != models.MatchAll {
+			rule.Match = r.Match
+		}
+		if r.Category != nil {
+			rule.Category = r.Category.Name
+		}
+		set.Rules = append(set.Rules, rule)
+	}
+	return set
+}
+
+// ToRules turns the rule set into ledger rules and checks each of them:
+// patterns and conditions must compile, and every category a rule names
+// must be one of categories. Rules are ordered by priority, keeping the
+// file order among equal priorities; problems name the rule.
+func (s *RuleSet) ToRules(categories map[string]uint) ([]models.CategoryRule, []string) {
+	var list []models.CategoryRule
+	var problems []string
+	names := make(map[string]bool, len(s.Rules))
+
+	for i, r := range s.Rules {
+		owner := fmt.Sprintf("rule #%d", i+1)
+		if r.Name != "" {
+			owner = fmt.Sprintf("rule #%d '%s'", i+1, r.Name)
+			if names[r.Name] {
+				problems = append(problems, owner+": name listed twice")
+			}
+			names[r.Name] = true
+		}
+
+		rule := models.CategoryRule{
+			Name:       r.Name,
+			Pattern:    r.Pattern,
+			Priority:   r.Priority,
+			Enabled:    !r.Disabled,
+			Match:      r.Match,
+			Conditions: r.Conditions,
+			Actions:    r.Actions,
+			Stop:       r.Stop,
+		}
+		if rule.Match == "" {
+			rule.Match = models.MatchAll
+		}
+
+		var ruleProblems []string
+		if rule.Pattern == "" && len(rule.Conditions) == 0 {
+			ruleProblems = append(ruleProblems, "needs a pattern or conditions")
+		}
+		if r.Category == "" && len(rule.Actions) == 0 {
+			ruleProblems = append(ruleProblems, "needs a category or actions")
+		}
+		if err := rules.Check(rule); err != nil {
+			// Check names the rule itself; keep only what is wrong
+			var cause error = err
+			if inner := errors.Unwrap(err); inner != nil {
+				cause = inner
+			}
+			ruleProblems = append(ruleProblems, cause.Error())
+		}
+		for _, name := range categoryNames(r) {
+			id, ok := categories[name]
+			if !ok {
+				ruleProblems = append(ruleProblems, fmt.Sprintf("unknown category '%s'", name))
+				continue
+			}
+			if name == r.Category {
+				rule.CategoryID = &id
+			}
+		}
+		for _, p := range ruleProblems {
+			problems = append(problems, owner+": "+p)
+		}
+
+		list = append(list, rule)
+	}
+
+	sortByPriority(list)
+	return list, problems
+}
+
+// categoryNames are the categories a rule refers to: its own and those of
+// its category and split actions
+func categoryNames(r Rule) []string {
+	var names []string
+	if r.Category != "" {
+		names = append(names, r.Category)
+	}
+	for _, a := range r.Actions {
+		switch a.Type {
+		case models.ActionCategory:
+			names = append(names, a.Value)
+		case models.ActionSplit:
+			for _, part := range a.Splits {
+				names = append(names, part.Category)
+			}
+		}
+	}
+	return names
+}
+
+func sortByPriority(list []models.CategoryRule) {
+	// rules without a priority keep the place they have in the file
+	last := 0
+	for i := range list {
+		if list[i].Priority == 0 {
+			list[i].Priority = last
+		}
+		last = list[i].Priority
+	}
+	slices.SortStableFunc(list, func(a, b models.CategoryRule) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+}
+
+// Write encodes the rule set as YAML or indented JSON
+func (s *RuleSet) Write(w io.Writer, format string) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(s)
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Read decodes a rule set and checks that its version can be read. Unknown
+// fields are an error, so a misspelled key does not silently drop a
+// condition.
+func Read(r io.Reader, format string) (*RuleSet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var s RuleSet
+	if format == FormatJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&s)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule set: %w", err)
+	}
+
+	switch {
+	case s.Version == 0:
+		return nil, errors.New("invalid rule set: version missing")
+	case s.Version > Version:
+		return nil, fmt.Errorf("rule set version %d is newer than the supported version %d", s.Version, Version)
+	}
+	return &s, nil
+}
@@ -37,3 +37,19 @@ func FilterByYear(txs []models.Transaction, year int) []models.Transaction {
 	}
 	return filtered
 }
+
+// FilterBetween returns transactions from one date up to and including
+// another; a zero date leaves that side open
+func FilterBetween(txs []models.Transaction, from, to time.Time) []models.Transaction {
+	var filtered []models.Transaction
+	for _, tx := range txs {
+		if !from.IsZero() && tx.Date.Before(from) {
+			continue
+		}
+		if !to.IsZero() && tx.Date.After(to) {
+			continue
+		}
+		filtered = append(filtered, tx)
+	}
+	return filtered
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/db"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AssignBudgetModel funds a category from the to be assigned pool in
+// zero-based budgeting, for the budget period shown in the overview
+type AssignBudgetModel struct {
+	inputs []textinput.Model
+	focus  int
+	errMsg string
+	// offset is the budget period assigned to, relative to the current one
+	offset int
+
+	// Done is set once the form is closed; Info tells what was assigned
+	Done bool
+	Info string
+}
+
+func NewAssignBudgetModel(offset int) *AssignBudgetModel {
+	category := textinput.New()
+	category.Placeholder = "Category"
+	category.Focus()
+
+	amount := textinput.New()
+	amount.Placeholder = "Amount (negative to return money to the pool)"
+
+	return &AssignBudgetModel{inputs: []textinput.Model{category, amount}, offset: offset}
+}
+
+func (m *AssignBudgetModel) Update(msg tea.Msg) (*AssignBudgetModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyTab, tea.KeyShiftTab:
+			m.inputs[m.focus].Blur()
+			m.focus = (m.focus + 1) % len(m.inputs)
+			m.inputs[m.focus].Focus()
+			return m, nil
+
+		case tea.KeyEsc:
+			m.Done = true
+			return m, nil
+
+		case tea.KeyEnter:
+			m.save()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+func (m *AssignBudgetModel) save() {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(m.inputs[1].Value()), 32)
+	if err != nil {
+		m.errMsg = "Invalid amount"
+		return
+	}
+	name := strings.TrimSpace(m.inputs[0].Value())
+	category, err := db.GetCategoryByName(name)
+	if err != nil {
+		m.errMsg = fmt.Sprintf("category '%s' not found", name)
+		return
+	}
+	period := db.BudgetPeriod(*category, time.Now()).Shift(m.offset)
+	if _, err := db.AssignBudget(name, float32(amount), period.Start); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.Done = true
+	m.Info = fmt.Sprintf("Assigned %.2f to %s for %s", amount, name, period.Label())
+}
+
+func (m *AssignBudgetModel) View() string {
+	view := "\n📥 Assign money from the pool\n\n"
+	for i, input := range m.inputs {
+		view += renderInput(input, i == m.focus) + "\n"
+	}
+	if m.errMsg != "" {
+		view += "\n" + errorStyle.Render("❌ "+m.errMsg) + "\n"
+	}
+	return view + "\n[Tab] Next • [Enter] Assign • [Esc] Cancel"
+}
+
+// poolView shows the to be assigned pool for the month shown in the budget
+// overview, with a warning when more was assigned than received
+func poolView(offset int) string {
+	month := budget.Containing(budget.Monthly, time.Time{}, time.Now()).Shift(offset)
+	pool, err := db.GetPool(month.Start)
+	if err != nil {
+		return errorStyle.Render("❌ Failed to load the pool: "+err.Error()) + "\n\n"
+	}
+
+	line := fmt.Sprintf("To be assigned (%s): %.2f  (received %.2f, assigned %.2f)",
+		month.Label(), pool.ToBeAssigned(), pool.Received, pool.Assigned)
+	if pool.Overassigned() {
+		return redStyle.Render(line) + "\n" +
+			redStyle.Render(fmt.Sprintf("⚠️ %.2f more assigned than received", -pool.ToBeAssigned())) + "\n\n"
+	}
+	return greenStyle.Render(line) + "\n\n"
+}
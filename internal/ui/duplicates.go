@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// duplicateWindowDays is how far apart two equal amounts may be booked
+// and still be reported as a possible duplicate.
+const duplicateWindowDays = 2
+
+type DuplicateReviewModel struct {
+	pairs  []models.DuplicatePair
+	cursor int
+	errMsg string
+}
+
+func NewDuplicateReviewModel() *DuplicateReviewModel {
+	m := &DuplicateReviewModel{}
+	m.load()
+	return m
+}
+
+func (m *DuplicateReviewModel) load() {
+	pairs, err := db.FindPossibleDuplicates(duplicateWindowDays)
+	if err != nil {
+		m.errMsg = "Failed to load duplicates: " + err.Error()
+		return
+	}
+	m.pairs = pairs
+	if m.cursor >= len(m.pairs) {
+		m.cursor = len(m.pairs) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m *DuplicateReviewModel) Update(msg tea.Msg) (*DuplicateReviewModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(m.pairs) == 0 {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down":
+		if m.cursor < len(m.pairs)-1 {
+			m.cursor++
+		}
+
+	case "x": // delete the newer transaction of the pair
+		pair := m.pairs[m.cursor]
+		if err := db.DeleteTransaction(pair.Second.ID); err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.load()
+
+	case "n": // not a duplicate, keep both
+		pair := m.pairs[m.cursor]
+		if err := db.DismissDuplicate(pair.First.ID, pair.Second.ID); err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.load()
+	}
+
+	return m, nil
+}
+
+func (m *DuplicateReviewModel) View() string {
+	view := "🔁 Possible Duplicates\n\n"
+
+	if m.errMsg != "" {
+		view += errorStyle.Render("❌ "+m.errMsg) + "\n\n"
+	}
+
+	if len(m.pairs) == 0 {
+		view += "No possible duplicates found.\n"
+		view += "\n[b] Back"
+		return view
+	}
+
+	for i, pair := range m.pairs {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		view += fmt.Sprintf("%s%s\n", cursor, formatDuplicateSide(pair.First))
+		view += fmt.Sprintf("  %s\n\n", formatDuplicateSide(pair.Second))
+	}
+
+	view += "[↑/↓] Move • [x] Delete newer • [n] Not a duplicate • [b] Back"
+	return view
+}
+
+func formatDuplicateSide(tx models.Transaction) string {
+	return fmt.Sprintf("#%-5d %10.2f | %s | %-15s %s",
+		tx.ID,
+		tx.Amount,
+		tx.Date.Format("2006-01-02"),
+		tx.Category.Name,
+		tx.Payee,
+	)
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// MoveMoneyModel moves budget money from one category envelope to another
+// for the current period, with the reason for the move
+type MoveMoneyModel struct {
+	inputs []textinput.Model
+	focus  int
+	errMsg string
+
+	// Done is set once the form is closed; Info tells what was moved
+	Done bool
+	Info string
+}
+
+func NewMoveMoneyModel(from string) *MoveMoneyModel {
+	source := textinput.New()
+	source.Placeholder = "From category"
+	source.SetValue(from)
+
+	target := textinput.New()
+	target.Placeholder = "To category"
+
+	amount := textinput.New()
+	amount.Placeholder = "Amount"
+
+	reason := textinput.New()
+	reason.Placeholder = "Reason, e.g. birthday gifts"
+	reason.CharLimit = 120
+
+	m := &MoveMoneyModel{inputs: []textinput.Model{source, target, amount, reason}}
+	if from != "" {
+		m.focus = 1
+	}
+	m.inputs[m.focus].Focus()
+	return m
+}
+
+func (m *MoveMoneyModel) Update(msg tea.Msg) (*MoveMoneyModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyTab, tea.KeyShiftTab:
+			step := 1
+			if keyMsg.Type == tea.KeyShiftTab {
+				step = len(m.inputs) - 1
+			}
+			m.inputs[m.focus].Blur()
+			m.focus = (m.focus + step) % len(m.inputs)
+			m.inputs[m.focus].Focus()
+			return m, nil
+
+		case tea.KeyEsc:
+			m.Done = true
+			return m, nil
+
+		case tea.KeyEnter:
+			m.save()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+func (m *MoveMoneyModel) save() {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(m.inputs[2].Value()), 32)
+	if err != nil {
+		m.errMsg = "Invalid amount"
+		return
+	}
+	move, err := db.MoveBudget(
+		strings.TrimSpace(m.inputs[0].Value()),
+		strings.TrimSpace(m.inputs[1].Value()),
+		float32(amount),
+		m.inputs[3].Value(),
+		time.Now(),
+	)
+	if err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.Done = true
+	m.Info = fmt.Sprintf("Moved %.2f from %s to %s", move.Amount, move.FromCategory.Name, move.ToCategory.Name)
+}
+
+func (m *MoveMoneyModel) View() string {
+	view := "\n💸 Move money between envelopes (current period)\n\n"
+	for i, input := range m.inputs {
+		view += renderInput(input, i == m.focus) + "\n"
+	}
+	if m.errMsg != "" {
+		view += "\n" + errorStyle.Render("❌ "+m.errMsg) + "\n"
+	}
+	return view + "\n[Tab] Next • [Enter] Move • [Esc] Cancel"
+}
+
+// movesView lists the money moved between envelopes during the periods of
+// the budget overview
+func movesView(stats []models.BudgetStats) string {
+	if len(stats) == 0 {
+		return ""
+	}
+	start, end := stats[0].Start, stats[0].End
+	for _, s := range stats[1:] {
+		if s.Start.Before(start) {
+			start = s.Start
+		}
+		if s.End.After(end) {
+			end = s.End
+		}
+	}
+
+	moves, err := db.GetEnvelopeTransfers(start, end)
+	if err != nil {
+		return "\n" + errorStyle.Render("❌ Failed to load money moves: "+err.Error()) + "\n"
+	}
+	if len(moves) == 0 {
+		return ""
+	}
+
+	view := "\n💸 Money moved\n\n"
+	for _, move := range moves {
+		view += fmt.Sprintf("%s %8.2f  %s → %s  %s\n",
+			move.Date.Format("2006-01-02"), move.Amount, move.FromCategory.Name, move.ToCategory.Name, move.Reason)
+	}
+	return view
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/export"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ExportModel writes a list of transactions to a file
+type ExportModel struct {
+	title   string
+	txs     []models.Transaction
+	formats []string
+	format  int
+
+	inputs []textinput.Model // file path, CSV columns
+	focus  int
+
+	errMsg string
+	done   string
+}
+
+func NewExportModel(title string, txs []models.Transaction) *ExportModel {
+	path := textinput.New()
+	path.Placeholder = "Export file path"
+	path.CharLimit = 256
+	path.SetValue("transactions.csv")
+	path.Focus()
+
+	columns := textinput.New()
+	columns.Placeholder = "CSV columns"
+	columns.SetValue(strings.Join(export.DefaultColumns, ","))
+
+	return &ExportModel{
+		title:   title,
+		txs:     txs,
+		formats: export.Formats(),
+		inputs:  []textinput.Model{path, columns},
+	}
+}
+
+func (m *ExportModel) Update(msg tea.Msg) (*ExportModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyTab: // cycle format and follow it with the file extension
+			m.format = (m.format + 1) % len(m.formats)
+			path := m.inputs[0].Value()
+			m.inputs[0].SetValue(strings.TrimSuffix(path, filepath.Ext(path)) + "." + m.formats[m.format])
+			return m, nil
+
+		case tea.KeyUp, tea.KeyDown:
+			m.inputs[m.focus].Blur()
+			m.focus = (m.focus + 1) % len(m.inputs)
+			m.inputs[m.focus].Focus()
+			return m, nil
+
+		case tea.KeyEnter:
+			m.write()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+func (m *ExportModel) write() {
+	m.errMsg, m.done = "", ""
+
+	path := strings.TrimSpace(m.inputs[0].Value())
+	if path == "" {
+		m.errMsg = "File path cannot be empty"
+		return
+	}
+	columns, err := export.ParseColumns(m.inputs[1].Value())
+	if err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	categories, err := db.GetAllCategories()
+	if err != nil {
+		m.errMsg = "Failed to load categories: " + err.Error()
+		return
+	}
+
+	format := m.formats[m.format]
+	if err := export.WriteFile(path, format, categories, m.txs, export.Options{Columns: columns}); err != nil {
+		m.errMsg = "Export failed: " + err.Error()
+		return
+	}
+	m.done = fmt.Sprintf("Exported %d transactions to %s", len(m.txs), path)
+}
+
+func (m *ExportModel) View() string {
+	view := fmt.Sprintf("📤 Export %s (%d transactions)\n\n", m.title, len(m.txs))
+	view += fmt.Sprintf("Format: %s\n\n", m.formats[m.format])
+
+	view += renderInput(m.inputs[0], m.focus == 0) + "\n"
+	if m.formats[m.format] == "csv" {
+		view += renderInput(m.inputs[1], m.focus == 1) + "\n"
+		view += "Columns: category, amount, date, payee, account, reference, id\n"
+	}
+
+	if m.errMsg != "" {
+		view += "\n" + errorStyle.Render("❌ "+m.errMsg) + "\n"
+	}
+	if m.done != "" {
+		view += "\n" + greenStyle.Render("✅ "+m.done) + "\n"
+	}
+
+	view += "\n[Tab] Change format • [↑/↓] Switch field • [Enter] Export • [Esc] Back"
+	return view
+}
@@ -0,0 +1,307 @@
+package ui
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// GoalsModel lists the savings goals with their progress and records
+// contributions to them
+type GoalsModel struct {
+	goals  []models.GoalStatus
+	cursor int
+	errMsg string
+	info   string
+
+	// editing is set while a form is open: the goal form (name, target,
+	// target date, category, account) or, when contributing, the
+	// contribution form (amount, date, note)
+	editing      bool
+	contributing bool
+	focus        int
+	inputs       []textinput.Model
+}
+
+func NewGoalsModel() *GoalsModel {
+	m := &GoalsModel{}
+	m.load()
+	return m
+}
+
+func (m *GoalsModel) load() {
+	goals, err := db.GetGoalStatuses(time.Now())
+	if err != nil {
+		m.errMsg = "Failed to load goals: " + err.Error()
+		return
+	}
+	m.goals = goals
+	m.cursor = min(max(m.cursor, 0), max(len(m.goals)-1, 0))
+}
+
+// Busy reports whether a text field has the keyboard
+func (m *GoalsModel) Busy() bool {
+	return m.editing
+}
+
+func (m *GoalsModel) Update(msg tea.Msg) (*GoalsModel, tea.Cmd) {
+	if m.editing {
+		return m.updateEdit(msg)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	m.info = ""
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.goals)-1 {
+			m.cursor++
+		}
+	case "a":
+		m.startGoal()
+		return m, textinput.Blink
+	case "c":
+		if len(m.goals) > 0 {
+			m.startContribution()
+			return m, textinput.Blink
+		}
+	case "x":
+		if len(m.goals) > 0 {
+			g := m.goals[m.cursor].Goal
+			if err := db.DeleteGoal(g.ID); err != nil {
+				m.errMsg = err.Error()
+				return m, nil
+			}
+			m.errMsg = ""
+			m.info = "Deleted goal " + g.Name
+			m.load()
+		}
+	}
+	return m, nil
+}
+
+func (m *GoalsModel) startGoal() {
+	name := textinput.New()
+	name.Placeholder = "Goal name, e.g. Car"
+
+	target := textinput.New()
+	target.Placeholder = "Target amount"
+
+	date := textinput.New()
+	date.Placeholder = "Target date (YYYY-MM-DD)"
+
+	category := textinput.New()
+	category.Placeholder = "Linked category (optional)"
+
+	account := textinput.New()
+	account.Placeholder = "Linked account (optional, instead of a category)"
+
+	m.open([]textinput.Model{name, target, date, category, account})
+	m.contributing = false
+}
+
+func (m *GoalsModel) startContribution() {
+	amount := textinput.New()
+	amount.Placeholder = "Amount (negative to take money out)"
+
+	date := textinput.New()
+	date.Placeholder = "Date (YYYY-MM-DD)"
+	date.SetValue(time.Now().Format("2006-01-02"))
+
+	note := textinput.New()
+	note.Placeholder = "Note (optional)"
+
+	m.open([]textinput.Model{amount, date, note})
+	m.contributing = true
+}
+
+func (m *GoalsModel) open(inputs []textinput.Model) {
+	m.inputs = inputs
+	m.focus = 0
+	m.inputs[0].Focus()
+	m.editing = true
+	m.errMsg = ""
+}
+
+func (m *GoalsModel) updateEdit(msg tea.Msg) (*GoalsModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyTab:
+			m.inputs[m.focus].Blur()
+			m.focus = (m.focus + 1) % len(m.inputs)
+			m.inputs[m.focus].Focus()
+			return m, nil
+
+		case tea.KeyEsc:
+			m.editing = false
+			m.errMsg = ""
+			return m, nil
+
+		case tea.KeyEnter:
+			if m.contributing {
+				m.contribute()
+			} else {
+				m.save()
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+func (m *GoalsModel) save() {
+	target, err := strconv.ParseFloat(strings.TrimSpace(m.inputs[1].Value()), 32)
+	if err != nil {
+		m.errMsg = "Invalid target amount"
+		return
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(m.inputs[2].Value()))
+	if err != nil {
+		m.errMsg = "Invalid target date, use YYYY-MM-DD"
+		return
+	}
+	g := models.Goal{
+		Name:       m.inputs[0].Value(),
+		Target:     float32(target),
+		TargetDate: date,
+		Account:    m.inputs[4].Value(),
+	}
+	if err := db.CreateGoal(&g, m.inputs[3].Value()); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.editing = false
+	m.errMsg = ""
+	m.info = "Added goal " + g.Name
+	m.load()
+	for i, s := range m.goals {
+		if s.Goal.ID == g.ID {
+			m.cursor = i
+		}
+	}
+}
+
+func (m *GoalsModel) contribute() {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(m.inputs[0].Value()), 32)
+	if err != nil {
+		m.errMsg = "Invalid amount"
+		return
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(m.inputs[1].Value()))
+	if err != nil {
+		m.errMsg = "Invalid date, use YYYY-MM-DD"
+		return
+	}
+	g := m.goals[m.cursor].Goal
+	if err := db.AddContribution(g.ID, float32(amount), date, m.inputs[2].Value()); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.editing = false
+	m.errMsg = ""
+	m.info = fmt.Sprintf("Added %.2f to %s", amount, g.Name)
+	m.load()
+}
+
+func (m *GoalsModel) View() string {
+	view := "🎯 Savings Goals\n\n"
+
+	if m.errMsg != "" {
+		view += errorStyle.Render("❌ "+m.errMsg) + "\n\n"
+	} else if m.info != "" {
+		view += greenStyle.Render(m.info) + "\n\n"
+	}
+
+	if len(m.goals) == 0 {
+		view += "No goals yet.\n"
+	} else {
+		view += m.goalsView()
+	}
+
+	if m.editing {
+		title := "Add goal"
+		if m.contributing {
+			title = "Contribute to " + m.goals[m.cursor].Goal.Name
+		}
+		view += fmt.Sprintf("\n✏️ %s\n\n", title)
+		for i, input := range m.inputs {
+			view += renderInput(input, i == m.focus) + "\n"
+		}
+		return view + "\n[Tab] Next • [Enter] Save • [Esc] Cancel"
+	}
+
+	return view + "\n[↑/↓] Move • [a] Add goal • [c] Contribute • [x] Delete • [b] Back"
+}
+
+func (m *GoalsModel) goalsView() string {
+	view := headerStyle.Render(fmt.Sprintf("  %-18s %9s / %-9s %5s  %-10s %9s  %-8s  %s",
+		"Goal", "Saved", "Target", "%", "By", "Per month", "Status", "Progress")) + "\n"
+
+	barWidth := 30
+	for i, s := range m.goals {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		progress := s.Progress()
+
+		status, style := "behind", orangeStyle
+		switch {
+		case progress >= 100:
+			status, style = "reached", greenStyle
+		case s.MonthsLeft == 0:
+			status, style = "overdue", redStyle
+		case s.OnTrack:
+			status, style = "on track", greenStyle
+		}
+
+		filled := min(max(int(progress/100*float32(barWidth)), 1), barWidth)
+		bar := strings.Repeat("─", filled)
+
+		line := fmt.Sprintf("%-18s %9.2f / %-9.2f %4.0f%%  %-10s %9.2f  %-8s",
+			s.Goal.Name, s.Saved, s.Goal.Target, progress, s.Goal.TargetDate.Format("2006-01-02"), s.Required, status)
+		view += cursor + style.Render(line) + "  " + style.Render(bar) + "\n"
+	}
+
+	return view + m.detailView(m.goals[m.cursor])
+}
+
+// detailView shows what a goal is linked to and its latest contributions
+func (m *GoalsModel) detailView(s models.GoalStatus) string {
+	g := s.Goal
+	view := fmt.Sprintf("\n%s: started %s, %d month(s) left, %.2f expected by now\n",
+		g.Name, g.StartDate.Format("2006-01-02"), s.MonthsLeft, s.Expected)
+	switch {
+	case g.Category != nil:
+		view += "Counts the transactions of category " + g.Category.Name + "\n"
+	case g.Account != "":
+		view += "Counts the transactions of account " + g.Account + "\n"
+	}
+
+	for i, c := range g.Contributions {
+		if i == 5 {
+			view += fmt.Sprintf("  … %d more\n", len(g.Contributions)-i)
+			break
+		}
+		view += fmt.Sprintf("  %s %9.2f  %s\n", c.Date.Format("2006-01-02"), c.Amount, c.Note)
+	}
+	return view
+}
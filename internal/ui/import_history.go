@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ImportHistoryModel lists past import batches and reverts them
+type ImportHistoryModel struct {
+	batches    []models.ImportBatch
+	cursor     int
+	confirming bool
+	errMsg     string
+	infoMsg    string
+}
+
+func NewImportHistoryModel() *ImportHistoryModel {
+	m := &ImportHistoryModel{}
+	m.load()
+	return m
+}
+
+func (m *ImportHistoryModel) load() {
+	batches, err := db.GetImportBatches()
+	if err != nil {
+		m.errMsg = "Failed to load import history: " + err.Error()
+		return
+	}
+	m.batches = batches
+	if m.cursor >= len(m.batches) {
+		m.cursor = len(m.batches) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m *ImportHistoryModel) Update(msg tea.Msg) (*ImportHistoryModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(m.batches) == 0 {
+		return m, nil
+	}
+
+	if m.confirming {
+		m.confirming = false
+		if keyMsg.String() != "y" {
+			return m, nil
+		}
+
+		batch := m.batches[m.cursor]
+		if err := db.RevertImportBatch(batch.ID); err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.infoMsg = fmt.Sprintf("✅ Reverted import #%d", batch.ID)
+		m.load()
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down":
+		if m.cursor < len(m.batches)-1 {
+			m.cursor++
+		}
+
+	case "r":
+		if m.batches[m.cursor].RevertedAt != nil {
+			m.errMsg = "This import was already reverted"
+			return m, nil
+		}
+		m.errMsg = ""
+		m.infoMsg = ""
+		m.confirming = true
+	}
+
+	return m, nil
+}
+
+func (m *ImportHistoryModel) View() string {
+	view := "🗂 Import History\n\n"
+
+	if m.errMsg != "" {
+		view += errorStyle.Render("❌ "+m.errMsg) + "\n\n"
+	}
+	if m.infoMsg != "" {
+		view += m.infoMsg + "\n\n"
+	}
+
+	if len(m.batches) == 0 {
+		return view + "No imports yet.\n\n[b] Back"
+	}
+
+	view += headerStyle.Render(fmt.Sprintf("  %-5s %-16s %-24s %-10s %8s %8s %8s",
+		"#", "Date", "File", "Profile", "Imported", "Skipped", "Failed"))
+	view += "\n"
+
+	for i, batch := range m.batches {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		line := fmt.Sprintf("%s%-5d %-16s %-24s %-10s %8d %8d %8d",
+			cursor,
+			batch.ID,
+			batch.CreatedAt.Format("2006-01-02 15:04"),
+			filepath.Base(batch.FileName),
+			batch.Profile,
+			batch.Imported,
+			batch.Skipped,
+			batch.Failed,
+		)
+		if batch.RevertedAt != nil {
+			line += "  " + orangeStyle.Render("reverted "+batch.RevertedAt.Format("2006-01-02"))
+		}
+		view += line + "\n"
+	}
+
+	if m.confirming {
+		view += fmt.Sprintf("\nRevert import #%d and remove its transactions? [y] Yes • [n] No",
+			m.batches[m.cursor].ID)
+		return view
+	}
+
+	view += "\n[↑/↓] Move • [r] Revert import • [b] Back"
+	return view
+}
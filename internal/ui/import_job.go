@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"peronal_finance_cli_manager/internal/importer"
+	"peronal_finance_cli_manager/internal/models"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// importProgressMsg reports how far a running import job is
+type importProgressMsg struct {
+	done  int64
+	total int64
+	rows  int
+}
+
+// importParsedMsg ends a parse job with the rows of the file and which of
+// them were imported before
+type importParsedMsg struct {
+	preview *importer.Preview
+	err     error
+}
+
+// importCommittedMsg ends a commit job
+type importCommittedMsg struct {
+	summary models.ImportSummary
+	err     error
+}
+
+// importJob runs a slow import step in a goroutine so the UI keeps
+// responding, and turns its progress into Bubble Tea messages.
+type importJob struct {
+	title   string
+	updates chan tea.Msg
+	cancel  context.CancelFunc
+	bar     progress.Model
+	started time.Time
+
+	done  int64
+	total int64
+	rows  int
+}
+
+func newImportJob(title string, run func(ctx context.Context, report func(importProgressMsg)) tea.Msg) *importJob {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &importJob{
+		title:   title,
+		updates: make(chan tea.Msg, 1),
+		cancel:  cancel,
+		bar:     progress.New(progress.WithDefaultGradient(), progress.WithWidth(50)),
+		started: time.Now(),
+	}
+
+	go func() {
+		report := func(p importProgressMsg) {
+			// drop updates the UI has not caught up with yet
+			select {
+			case job.updates <- p:
+			default:
+			}
+		}
+		result := run(ctx, report)
+		// the final message must not be dropped
+		job.updates <- result
+		close(job.updates)
+	}()
+
+	return job
+}
+
+// wait delivers the next message of the job
+func (j *importJob) wait() tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-j.updates
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+func (j *importJob) progress(msg importProgressMsg) tea.Cmd {
+	j.done, j.total, j.rows = msg.done, msg.total, msg.rows
+	return j.wait()
+}
+
+func (j *importJob) View() string {
+	view := fmt.Sprintf("⏳ %s\n\n", j.title)
+
+	percent := 0.0
+	if j.total > 0 {
+		percent = float64(j.done) / float64(j.total)
+	}
+	view += j.bar.ViewAs(percent) + "\n\n"
+
+	elapsed := time.Since(j.started).Seconds()
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(j.rows) / elapsed
+	}
+	view += fmt.Sprintf("%d rows • %.0f rows/s • %.1fs\n", j.rows, rate, elapsed)
+
+	view += "\n[Esc] Cancel"
+	return view
+}
+
+// startParseJob reads a statement file and looks up which rows are known
+func startParseJob(filePath string, profile importer.Profile) *importJob {
+	return newImportJob("Reading "+filePath, func(ctx context.Context, report func(importProgressMsg)) tea.Msg {
+		preview, err := importer.ReadPreview(ctx, filePath, profile, func(read, size int64, rows int) {
+			report(importProgressMsg{done: read, total: size, rows: rows})
+		})
+		return importParsedMsg{preview: preview, err: err}
+	})
+}
+
+// startCommitJob writes the accepted rows of a preview in one DB transaction
+func startCommitJob(preview *importer.Preview, txs []models.Transaction, rejected int, failures []string) *importJob {
+	return newImportJob("Importing "+preview.FilePath, func(ctx context.Context, report func(importProgressMsg)) tea.Msg {
+		summary, err := preview.Commit(ctx, txs, rejected, failures, func(done, total int) {
+			report(importProgressMsg{done: int64(done), total: int64(total), rows: done})
+		})
+		return importCommittedMsg{summary: summary, err: err}
+	})
+}
@@ -0,0 +1,402 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/importer"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type previewRow struct {
+	row         importer.Record
+	accepted    bool
+	duplicate   bool
+	newCategory bool
+
+	// what the rules will do with the row on import
+	ruleCategory string
+	ruleSkip     bool
+}
+
+// ImportPreviewModel lists the parsed rows of a statement file so they can be
+// accepted, rejected or fixed before anything is written to the database.
+type ImportPreviewModel struct {
+	preview *importer.Preview
+	rows    []previewRow
+	cursor  int
+	// height is the terminal height the rows are windowed to, 0 until the
+	// first tea.WindowSizeMsg
+	height int
+
+	editing    bool
+	editFocus  int
+	editInputs []textinput.Model
+	errMsg     string
+
+	job     *importJob
+	summary *models.ImportSummary
+}
+
+func NewImportPreviewModel(parsed importParsedMsg, height int) *ImportPreviewModel {
+	m := &ImportPreviewModel{preview: parsed.preview, height: height}
+	for _, row := range parsed.preview.Records {
+		pr := previewRow{row: row}
+		m.classify(&pr)
+		pr.accepted = pr.row.Err == nil && !pr.duplicate
+		m.rows = append(m.rows, pr)
+	}
+	return m
+}
+
+// classify looks up the duplicate and category status of a row and what
+// the rules will do with it
+func (m *ImportPreviewModel) classify(pr *previewRow) {
+	pr.duplicate = false
+	pr.newCategory = false
+	pr.ruleCategory = ""
+	pr.ruleSkip = false
+	if pr.row.Err != nil {
+		return
+	}
+
+	pr.duplicate = m.preview.Imported[pr.row.Transaction.ImportID]
+
+	name := pr.row.Transaction.Category.Name
+	pr.newCategory = name != "" && !m.preview.Categories[name]
+
+	if m.preview.Profile.Verbatim {
+		// the rows are stored as they are
+		return
+	}
+	if res, err := db.ApplyRules(pr.row.Transaction); err == nil {
+		pr.ruleSkip = res.Skip
+		pr.ruleCategory = res.Transaction.Category.Name
+		if len(res.Splits) > 0 {
+			pr.ruleCategory = "split"
+		}
+	}
+}
+
+// Busy reports whether a row is being edited or the import is running,
+// so keys must not leave the screen
+func (m *ImportPreviewModel) Busy() bool {
+	return m.editing || m.job != nil
+}
+
+// SetHeight fits the list of rows to a terminal of the given height
+func (m *ImportPreviewModel) SetHeight(height int) {
+	m.height = height
+}
+
+// window returns the rows shown, [start, end), around the cursor
+func (m *ImportPreviewModel) window() (int, int) {
+	// title, error, header, position and help lines
+	size := m.height - 9
+	if m.editing {
+		size -= len(m.editInputs) + 4
+	}
+	if m.height == 0 {
+		size = 20
+	}
+	size = max(size, 3)
+	if size >= len(m.rows) {
+		return 0, len(m.rows)
+	}
+	start := min(max(m.cursor-size/2, 0), len(m.rows)-size)
+	return start, start + size
+}
+
+func (m *ImportPreviewModel) Update(msg tea.Msg) (*ImportPreviewModel, tea.Cmd) {
+	if m.job != nil {
+		return m.updateJob(msg)
+	}
+	if m.editing {
+		return m.updateEdit(msg)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.summary != nil || len(m.rows) == 0 {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+
+	case "pgup", "pgdown":
+		start, end := m.window()
+		page := end - start
+		if keyMsg.String() == "pgup" {
+			page = -page
+		}
+		m.cursor = min(max(m.cursor+page, 0), len(m.rows)-1)
+
+	case " ": // toggle accept / reject
+		pr := &m.rows[m.cursor]
+		if pr.row.Err != nil {
+			m.errMsg = "Fix the row before accepting it"
+			return m, nil
+		}
+		pr.accepted = !pr.accepted
+		m.errMsg = ""
+
+	case "e":
+		m.startEdit()
+		return m, textinput.Blink
+
+	case "enter":
+		return m, m.commit()
+	}
+
+	return m, nil
+}
+
+func (m *ImportPreviewModel) updateJob(msg tea.Msg) (*ImportPreviewModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case importProgressMsg:
+		return m, m.job.progress(msg)
+
+	case importCommittedMsg:
+		m.job = nil
+		if errors.Is(msg.err, context.Canceled) {
+			m.errMsg = "Import cancelled, nothing was written"
+			return m, nil
+		}
+		if msg.err != nil {
+			m.errMsg = "Import rolled back: " + msg.err.Error()
+			return m, nil
+		}
+		m.summary = &msg.summary
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyEsc {
+			m.job.cancel()
+		}
+	}
+	return m, nil
+}
+
+func (m *ImportPreviewModel) startEdit() {
+	tx := m.rows[m.cursor].row.Transaction
+
+	category := textinput.New()
+	category.Placeholder = "Category Name (empty = rules or inbox)"
+	category.SetValue(tx.Category.Name)
+
+	amount := textinput.New()
+	amount.Placeholder = "Amount"
+	date := textinput.New()
+	date.Placeholder = "Date (YYYY-MM-DD)"
+	if m.rows[m.cursor].row.Err == nil || tx.Amount != 0 {
+		amount.SetValue(fmt.Sprintf("%.2f", tx.Amount))
+	}
+	if !tx.Date.IsZero() {
+		date.SetValue(tx.Date.Format("2006-01-02"))
+	}
+
+	m.editInputs = []textinput.Model{category, amount, date}
+	m.editFocus = 0
+	m.editInputs[0].Focus()
+	m.editing = true
+	m.errMsg = ""
+}
+
+func (m *ImportPreviewModel) updateEdit(msg tea.Msg) (*ImportPreviewModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyTab:
+			m.editInputs[m.editFocus].Blur()
+			m.editFocus = (m.editFocus + 1) % len(m.editInputs)
+			m.editInputs[m.editFocus].Focus()
+			return m, nil
+
+		case tea.KeyEsc:
+			m.editing = false
+			m.errMsg = ""
+			return m, nil
+
+		case tea.KeyEnter:
+			m.applyEdit()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.editInputs[m.editFocus], cmd = m.editInputs[m.editFocus].Update(msg)
+	return m, cmd
+}
+
+func (m *ImportPreviewModel) applyEdit() {
+	category := strings.TrimSpace(m.editInputs[0].Value())
+	amount, err := importer.ParseAmount(m.editInputs[1].Value())
+	if err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+	date, err := importer.ParseDate(m.editInputs[2].Value())
+	if err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	pr := &m.rows[m.cursor]
+	if _, ok := m.preview.Categories[category]; !ok && category != "" {
+		_, err := db.GetCategoryByName(category)
+		m.preview.Categories[category] = err == nil
+	}
+
+	tx := pr.row.Transaction
+	tx.Category = models.Category{Name: category}
+	tx.Amount = amount
+	tx.Date = date
+	if tx.ImportID == "" || strings.HasPrefix(tx.ImportID, "sha256:") {
+		// the row changed, so its fingerprint changes with it
+		taken := make(map[string]bool, len(m.rows))
+		for i, other := range m.rows {
+			if i != m.cursor && other.row.Err == nil {
+				taken[other.row.Transaction.ImportID] = true
+			}
+		}
+		tx.ImportID = importer.Refingerprint(tx, taken)
+	}
+
+	pr.row.Transaction = tx
+	pr.row.Err = nil
+	if imported, err := db.TransactionImported(tx.ImportID); err == nil {
+		m.preview.Imported[tx.ImportID] = imported
+	}
+	m.classify(pr)
+	pr.accepted = !pr.duplicate
+
+	m.editing = false
+	m.errMsg = ""
+}
+
+// commit starts writing the accepted rows; rejected and broken rows are
+// counted in the summary
+func (m *ImportPreviewModel) commit() tea.Cmd {
+	var accepted []models.Transaction
+	var failures []string
+	rejected := 0
+
+	for _, pr := range m.rows {
+		switch {
+		case pr.row.Err != nil:
+			failures = append(failures, fmt.Sprintf("line %d: %v", pr.row.Line, pr.row.Err))
+		case !pr.accepted:
+			rejected++
+		default:
+			accepted = append(accepted, pr.row.Transaction)
+		}
+	}
+
+	m.errMsg = ""
+	m.job = startCommitJob(m.preview, accepted, rejected, failures)
+	return m.job.wait()
+}
+
+func (m *ImportPreviewModel) View() string {
+	if m.summary != nil {
+		return renderImportSummary(*m.summary) + "\n\n[b] Back"
+	}
+	if m.job != nil {
+		return m.job.View()
+	}
+
+	view := "📥 Import Preview\n\n"
+
+	if m.errMsg != "" {
+		view += errorStyle.Render("❌ "+m.errMsg) + "\n\n"
+	}
+
+	if len(m.rows) == 0 {
+		return view + "The file has no rows.\n\n[b] Back"
+	}
+
+	view += headerStyle.Render(fmt.Sprintf("  %-5s %-3s %-18s %10s  %-10s  %s",
+		"Line", "", "Category", "Amount", "Date", "Status"))
+	view += "\n"
+
+	start, end := m.window()
+	for i := start; i < end; i++ {
+		pr := m.rows[i]
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		mark := "[ ]"
+		if pr.accepted {
+			mark = "[x]"
+		}
+
+		tx := pr.row.Transaction
+		category := tx.Category.Name
+		switch {
+		case category == "" && pr.ruleCategory != "":
+			category = pr.ruleCategory + " (rule)"
+		case category == "":
+			category = "(inbox)"
+		case pr.newCategory:
+			category += " (new)"
+		}
+		date := ""
+		if !tx.Date.IsZero() {
+			date = tx.Date.Format("2006-01-02")
+		}
+
+		line := fmt.Sprintf("%s%-5d %-3s %-18s %10.2f  %-10s  ",
+			cursor, pr.row.Line, mark, category, tx.Amount, date)
+
+		switch {
+		case pr.row.Err != nil:
+			view += line + redStyle.Render("error: "+pr.row.Err.Error()) + "\n"
+		case pr.duplicate:
+			view += line + orangeStyle.Render("duplicate") + "\n"
+		case pr.ruleSkip:
+			view += line + orangeStyle.Render("skipped by rule") + "\n"
+		default:
+			view += line + greenStyle.Render("ok") + "\n"
+		}
+	}
+
+	if start > 0 || end < len(m.rows) {
+		view += fmt.Sprintf("rows %d-%d of %d\n", start+1, end, len(m.rows))
+	}
+
+	if m.editing {
+		view += fmt.Sprintf("\n✏️ Edit line %d\n\n", m.rows[m.cursor].row.Line)
+		for i, input := range m.editInputs {
+			view += renderInput(input, i == m.editFocus) + "\n"
+		}
+		view += "\n[Tab] Next • [Enter] Apply • [Esc] Cancel"
+		return view
+	}
+
+	view += "\n[↑/↓] Move • [PgUp/PgDn] Page • [Space] Accept/Reject • [e] Edit • [Enter] Import accepted • [b] Back"
+	return view
+}
+
+func renderImportSummary(summary models.ImportSummary) string {
+	view := "📥 Import finished\n\n"
+	view += greenStyle.Render(fmt.Sprintf("Imported: %d", summary.Imported)) + "\n"
+	view += orangeStyle.Render(fmt.Sprintf("Skipped:  %d", summary.Skipped)) + "\n"
+	view += redStyle.Render(fmt.Sprintf("Failed:   %d", summary.Failed))
+	for _, e := range summary.Errors {
+		view += "\n  " + e
+	}
+	return view
+}
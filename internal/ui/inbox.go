@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// InboxModel lists the transactions no rule could categorize so they can
+// be given a category, optionally teaching a rule for the payee on the way
+type InboxModel struct {
+	txs    []models.Transaction
+	cursor int
+	errMsg string
+	info   string
+
+	// assigning is set while the category form is open
+	assigning bool
+	focus     int
+	inputs    []textinput.Model // category, rule text
+}
+
+func NewInboxModel() *InboxModel {
+	category := textinput.New()
+	category.Placeholder = "Category Name"
+
+	rule := textinput.New()
+	rule.Placeholder = "Also add a rule for payees containing (empty = no rule)"
+
+	m := &InboxModel{inputs: []textinput.Model{category, rule}}
+	m.load()
+	return m
+}
+
+func (m *InboxModel) load() {
+	txs, err := db.GetInboxTransactions()
+	if err != nil {
+		m.errMsg = "Failed to load the inbox: " + err.Error()
+		return
+	}
+	m.txs = txs
+	if m.cursor >= len(m.txs) {
+		m.cursor = len(m.txs) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// Busy reports whether a text field has the keyboard
+func (m *InboxModel) Busy() bool {
+	return m.assigning
+}
+
+func (m *InboxModel) Update(msg tea.Msg) (*InboxModel, tea.Cmd) {
+	if m.assigning {
+		return m.updateAssign(msg)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	m.info = ""
+
+	switch keyMsg.String() {
+	case "R":
+		changed, err := db.TriageInbox()
+		if err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.info = fmt.Sprintf("Rules categorized %d transaction(s)", changed)
+		m.load()
+		return m, nil
+	}
+
+	if len(m.txs) == 0 {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down":
+		if m.cursor < len(m.txs)-1 {
+			m.cursor++
+		}
+
+	case "enter", "c":
+		m.inputs[0].SetValue("")
+		m.inputs[1].SetValue("")
+		m.inputs[1].Blur()
+		m.inputs[0].Focus()
+		m.focus = 0
+		m.assigning = true
+		m.errMsg = ""
+		return m, textinput.Blink
+	}
+
+	return m, nil
+}
+
+func (m *InboxModel) updateAssign(msg tea.Msg) (*InboxModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyTab:
+			m.inputs[m.focus].Blur()
+			m.focus = (m.focus + 1) % len(m.inputs)
+			m.inputs[m.focus].Focus()
+			if m.focus == 1 && m.inputs[1].Value() == "" {
+				// suggest the payee as it was imported
+				m.inputs[1].SetValue(strings.ToLower(strings.TrimSpace(m.txs[m.cursor].Payee)))
+				m.inputs[1].CursorEnd()
+			}
+			return m, nil
+
+		case tea.KeyEsc:
+			m.assigning = false
+			m.errMsg = ""
+			return m, nil
+
+		case tea.KeyEnter:
+			m.assign()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+// assign moves the selected transaction to the typed category and, when
+// rule text was given, adds a rule and lets it sweep the rest of the inbox
+func (m *InboxModel) assign() {
+	category := strings.TrimSpace(m.inputs[0].Value())
+	if category == "" {
+		m.errMsg = "Category cannot be empty"
+		return
+	}
+	tx := m.txs[m.cursor]
+	if err := db.AssignCategory(tx.ID, category); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.info = fmt.Sprintf("Moved to %s", category)
+	if text := strings.TrimSpace(m.inputs[1].Value()); text != "" {
+		rule := models.CategoryRule{
+			Name: text,
+			Conditions: []models.RuleCondition{
+				{Field: models.FieldPayee, Op: models.OpContains, Value: text},
+			},
+			Enabled: true,
+		}
+		if err := db.SaveCategoryRule(&rule, category); err != nil {
+			m.errMsg = "Rule not added: " + err.Error()
+			m.assigning = false
+			m.load()
+			return
+		}
+		changed, err := db.TriageInbox()
+		if err != nil {
+			m.errMsg = err.Error()
+		}
+		m.info += fmt.Sprintf(", rule added and %d more transaction(s) categorized", changed)
+	}
+
+	m.assigning = false
+	m.errMsg = ""
+	m.load()
+}
+
+func (m *InboxModel) View() string {
+	view := "📬 Inbox: transactions to categorize\n\n"
+
+	if m.errMsg != "" {
+		view += errorStyle.Render("❌ "+m.errMsg) + "\n\n"
+	} else if m.info != "" {
+		view += greenStyle.Render(m.info) + "\n\n"
+	}
+
+	if len(m.txs) == 0 {
+		return view + "Nothing to categorize.\n\n[R] Run rules • [b] Back"
+	}
+
+	view += headerStyle.Render(fmt.Sprintf("  %-10s %10s  %-28s %s", "Date", "Amount", "Payee", "Account"))
+	view += "\n"
+	for i, tx := range m.txs {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		payee := tx.Payee
+		if payee == "" {
+			payee = tx.Description
+		}
+		view += fmt.Sprintf("%s%-10s %10.2f  %-28s %s\n",
+			cursor, tx.Date.Format("2006-01-02"), tx.Amount, payee, tx.Account)
+	}
+
+	if m.assigning {
+		view += "\n🏷 Categorize\n\n"
+		for i, input := range m.inputs {
+			view += renderInput(input, i == m.focus) + "\n"
+		}
+		view += "\n[Tab] Next • [Enter] Save • [Esc] Cancel"
+		return view
+	}
+
+	view += "\n[↑/↓] Move • [Enter] Categorize • [R] Run rules • [b] Back"
+	return view
+}
@@ -1,10 +1,14 @@
 package ui
 
 import (
+	"cmp"
 	"fmt"
+	"peronal_finance_cli_manager/internal/budget"
 	"peronal_finance_cli_manager/internal/db"
 	"peronal_finance_cli_manager/internal/models"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	_ "github.com/charmbracelet/lipgloss"
@@ -22,31 +26,26 @@ var (
 			Foreground(lipgloss.Color("1"))
 )
 
-type FileInputModel struct {
-	input  textinput.Model
-	errMsg string
-	focus  bool
-}
-
-func NewFileInputModel() *FileInputModel {
-	ti := textinput.New()
-	ti.Placeholder = "Enter CSV/OFX file path"
-	ti.Focus()
-	return &FileInputModel{
-		input: ti,
-		focus: true,
-	}
-}
-
 type TransactionInputModel struct {
 	inputCategory textinput.Model
 	inputAmount   textinput.Model
 	inputDate     textinput.Model
 	inputDesc     textinput.Model
 
-	recommendedCategory string
-	focusIndex          int
-	errMsg              string
+	suggestions []categorySuggestion
+	suggestion  int // the highlighted suggestion
+	focusIndex  int
+	errMsg      string
+}
+
+// suggestionCount is how many learned categories are suggested
+const suggestionCount = 3
+
+// categorySuggestion is a category proposed for the transaction being
+// entered, by a rule or by the classifier
+type categorySuggestion struct {
+	name   string
+	source string
 }
 
 func NewTransactionInputModel() *TransactionInputModel {
@@ -84,6 +83,13 @@ func NewTransactionInputModel() *TransactionInputModel {
 type InputModel struct {
 	input       textinput.Model
 	inputBudget textinput.Model
+	inputPeriod textinput.Model // only on update
+	inputAnchor textinput.Model // only on update
+	inputScope  textinput.Model // only on update
+	inputRoll   textinput.Model // only on update
+	inputKind   textinput.Model // only on update
+	inputAlerts textinput.Model // only on update
+	inputGroup  textinput.Model // only on update
 	focusIndex  int
 	errMsg      string
 }
@@ -95,14 +101,43 @@ func NewInputModelPtr() *InputModel {
 	ti.CharLimit = 64
 	ti.Focus()
 
-	budget := textinput.New()
-	budget.Placeholder = "Budget"
-	budget.CharLimit = 10
-	budget.Blur()
+	amount := textinput.New()
+	amount.Placeholder = "Budget"
+	amount.CharLimit = 10
+	amount.Blur()
+
+	period := textinput.New()
+	period.Placeholder = "Budget period: " + strings.Join(budget.Periods, ", ")
+
+	anchor := textinput.New()
+	anchor.Placeholder = "Period starts on (YYYY-MM-DD, empty = calendar)"
+
+	scope := textinput.New()
+	scope.Placeholder = "Applies: on = from this period on, only = this period only"
+
+	rollover := textinput.New()
+	rollover.Placeholder = "Rollover: none, surplus = unspent only, both = unspent and overspent"
+
+	kind := textinput.New()
+	kind.Placeholder = "Kind: expense, or income to fund zero-based budgets"
+
+	alerts := textinput.New()
+	alerts.Placeholder = "Alert at % of budget, e.g. 50, 80, 100, 120 (empty = " +
+		budget.FormatThresholds(budget.DefaultThresholds) + ")"
+
+	group := textinput.New()
+	group.Placeholder = "Template group: needs, wants, savings (empty = none)"
 
 	return &InputModel{
 		input:       ti,
-		inputBudget: budget,
+		inputBudget: amount,
+		inputPeriod: period,
+		inputAnchor: anchor,
+		inputScope:  scope,
+		inputRoll:   rollover,
+		inputKind:   kind,
+		inputAlerts: alerts,
+		inputGroup:  group,
 		focusIndex:  0,
 	}
 }
@@ -137,14 +172,188 @@ func (m *InputModel) Update(msg tea.Msg) (*InputModel, tea.Cmd, *models.Category
 func (m *InputModel) updateFocus() {
 	m.input.Blur()
 	m.inputBudget.Blur()
+	m.inputPeriod.Blur()
+	m.inputAnchor.Blur()
+	m.inputScope.Blur()
+	m.inputRoll.Blur()
+	m.inputKind.Blur()
+	m.inputAlerts.Blur()
+	m.inputGroup.Blur()
 
-	if m.focusIndex == 0 {
+	switch m.focusIndex {
+	case 0:
 		m.input.Focus()
-	} else {
+	case 1:
 		m.inputBudget.Focus()
+	case 2:
+		m.inputPeriod.Focus()
+	case 3:
+		m.inputAnchor.Focus()
+	case 4:
+		m.inputScope.Focus()
+	case 5:
+		m.inputRoll.Focus()
+	case 6:
+		m.inputKind.Focus()
+	case 7:
+		m.inputAlerts.Focus()
+	default:
+		m.inputGroup.Focus()
 	}
 }
 
+// startUpdate fills the budget fields of the update form with cat
+func (m *InputModel) startUpdate(cat models.Category) {
+	m.inputBudget.SetValue(fmt.Sprintf("%.0f", cat.Budget))
+	if cat.BudgetPercent > 0 {
+		m.inputBudget.SetValue(fmt.Sprintf("%g%%", cat.BudgetPercent))
+	}
+	m.inputPeriod.SetValue(cat.BudgetPeriod)
+	m.inputAnchor.SetValue("")
+	if !cat.BudgetAnchor.IsZero() {
+		m.inputAnchor.SetValue(cat.BudgetAnchor.Format("2006-01-02"))
+	}
+	m.inputScope.SetValue("on")
+	m.inputRoll.SetValue(cmp.Or(cat.Rollover, budget.RolloverNone))
+	m.inputKind.SetValue(cmp.Or(cat.Kind, models.KindExpense))
+	m.inputAlerts.SetValue(budget.FormatThresholds(cat.AlertThresholds))
+	m.inputGroup.SetValue(cat.BudgetGroup)
+	m.errMsg = ""
+
+	// the name cannot change
+	m.focusIndex = 1
+	m.updateFocus()
+}
+
+// updateBudget handles key presses in the update form, which has the
+// budget, period, anchor, scope, rollover, kind, alert and group fields
+func (m *InputModel) updateBudget(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Type == tea.KeyTab {
+		m.focusIndex = m.focusIndex%8 + 1
+		m.updateFocus()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	switch m.focusIndex {
+	case 2:
+		m.inputPeriod, cmd = m.inputPeriod.Update(msg)
+	case 3:
+		m.inputAnchor, cmd = m.inputAnchor.Update(msg)
+	case 4:
+		m.inputScope, cmd = m.inputScope.Update(msg)
+	case 5:
+		m.inputRoll, cmd = m.inputRoll.Update(msg)
+	case 6:
+		m.inputKind, cmd = m.inputKind.Update(msg)
+	case 7:
+		m.inputAlerts, cmd = m.inputAlerts.Update(msg)
+	case 8:
+		m.inputGroup, cmd = m.inputGroup.Update(msg)
+	default:
+		m.inputBudget, cmd = m.inputBudget.Update(msg)
+	}
+	return cmd
+}
+
+// budgetHistoryView lists the budget amounts set for a category
+func budgetHistoryView(cat models.Category) string {
+	history, err := db.GetBudgetHistory(cat.ID)
+	if err != nil || len(history) == 0 {
+		return ""
+	}
+	view := "\n\nBudget history:\n"
+	for _, h := range history {
+		when := "from " + h.Start.Format("2006-01-02")
+		switch {
+		case h.End != nil:
+			when = h.Start.Format("2006-01-02") + " – " + h.End.AddDate(0, 0, -1).Format("2006-01-02")
+		case h.Start.IsZero():
+			when = "at first"
+		}
+		view += fmt.Sprintf("  %-25s %10.2f\n", when, h.Amount)
+	}
+	return view
+}
+
+// submitBudget saves the update form for the category with id. A budget
+// such as 15% is that share of income; the amount set before is kept.
+func (m *InputModel) submitBudget(id uint) bool {
+	cat, err := db.GetCategory(id)
+	if err != nil {
+		m.errMsg = err.Error()
+		return false
+	}
+	amount, percent := float64(cat.Budget), 0.0
+	if value, ok := strings.CutSuffix(strings.TrimSpace(m.inputBudget.Value()), "%"); ok {
+		percent, err = strconv.ParseFloat(strings.TrimSpace(value), 32)
+	} else {
+		amount, err = strconv.ParseFloat(value, 32)
+	}
+	if err != nil {
+		m.errMsg = "Invalid budget, use an amount or a share of income such as 15%"
+		return false
+	}
+	if err := budget.CheckPercent(float32(percent)); err != nil {
+		m.errMsg = err.Error()
+		return false
+	}
+	var anchor time.Time
+	if value := strings.TrimSpace(m.inputAnchor.Value()); value != "" {
+		if anchor, err = time.Parse("2006-01-02", value); err != nil {
+			m.errMsg = "Invalid start date, use YYYY-MM-DD"
+			return false
+		}
+	}
+	var only bool
+	switch strings.ToLower(strings.TrimSpace(m.inputScope.Value())) {
+	case "", "on":
+	case "only":
+		only = true
+	default:
+		m.errMsg = "Applies must be on or only"
+		return false
+	}
+	rollover := strings.ToLower(strings.TrimSpace(m.inputRoll.Value()))
+	if err := budget.CheckRollover(rollover); err != nil {
+		m.errMsg = err.Error()
+		return false
+	}
+	kind := strings.ToLower(strings.TrimSpace(m.inputKind.Value()))
+	if kind != "" && kind != models.KindExpense && kind != models.KindIncome {
+		m.errMsg = "Kind must be expense or income"
+		return false
+	}
+	thresholds, err := budget.ParseThresholds(m.inputAlerts.Value())
+	if err != nil {
+		m.errMsg = err.Error()
+		return false
+	}
+	period := strings.ToLower(strings.TrimSpace(m.inputPeriod.Value()))
+	if err := db.UpdateCategoryBudget(id, float32(amount), period, anchor, only); err != nil {
+		m.errMsg = err.Error()
+		return false
+	}
+	if err := db.SetCategoryRollover(id, rollover); err != nil {
+		m.errMsg = err.Error()
+		return false
+	}
+	if err := db.SetCategoryKind(id, kind); err != nil {
+		m.errMsg = err.Error()
+		return false
+	}
+	if err := db.SetAlertThresholds(id, thresholds); err != nil {
+		m.errMsg = err.Error()
+		return false
+	}
+	if err := db.SetCategoryShare(id, float32(percent), m.inputGroup.Value()); err != nil {
+		m.errMsg = err.Error()
+		return false
+	}
+	m.errMsg = ""
+	return true
+}
+
 func (m *InputModel) submit() (
 	*InputModel,
 	tea.Cmd,
@@ -197,9 +406,9 @@ func (m *TransactionInputModel) Update(msg tea.Msg) (*TransactionInputModel, tea
 			//m.focusIndex = (m.focusIndex + 1) % 4
 			//m.updateFocus()
 			//return m, nil, nil, nil
-			// ACCEPT recommendation if focus is on description
-			if m.focusIndex == 0 && m.recommendedCategory != "" {
-				m.inputCategory.SetValue(m.recommendedCategory)
+			// ACCEPT the highlighted suggestion if focus is on description
+			if m.focusIndex == 0 && len(m.suggestions) > 0 {
+				m.inputCategory.SetValue(m.suggestions[m.suggestion].name)
 				m.focusIndex = 1
 			} else {
 				m.focusIndex = (m.focusIndex + 1) % 4
@@ -207,6 +416,16 @@ func (m *TransactionInputModel) Update(msg tea.Msg) (*TransactionInputModel, tea
 			m.updateFocus()
 			return m, nil, nil, nil
 
+		case tea.KeyUp, tea.KeyDown:
+			if m.focusIndex == 0 && len(m.suggestions) > 0 {
+				step := 1
+				if msg.Type == tea.KeyUp {
+					step = len(m.suggestions) - 1
+				}
+				m.suggestion = (m.suggestion + step) % len(m.suggestions)
+				return m, nil, nil, nil
+			}
+
 		case tea.KeyEnter:
 			return m.submit()
 
@@ -219,45 +438,68 @@ func (m *TransactionInputModel) Update(msg tea.Msg) (*TransactionInputModel, tea
 	var cmd tea.Cmd
 	m.inputDesc, cmd = m.inputDesc.Update(msg)
 
-	// Recalculate recommendation LIVE
-	m.recommendedCategory = db.RecommendCategory(m.inputDesc.Value())
-
 	m.inputCategory, _ = m.inputCategory.Update(msg)
 	m.inputAmount, _ = m.inputAmount.Update(msg)
 	m.inputDate, _ = m.inputDate.Update(msg)
 
+	// Recalculate suggestions LIVE
+	m.suggest()
+
 	return m, cmd, nil, nil
 }
 
-func (m *FileInputModel) Update(msg tea.Msg) (*FileInputModel, tea.Cmd, string, error) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyEnter:
-			path := m.input.Value()
-			if path == "" {
-				m.errMsg = "File path cannot be empty"
-				return m, nil, "", nil
-			}
-
-			// Import transactions via db package
-			imported, err := db.ImportTransactionsFromFile(path)
-			if err != nil {
-				m.errMsg = fmt.Sprintf("Import failed: %v", err)
-				return m, nil, "", err
-			}
+// suggest ranks the categories for the description and amount typed so
+// far: the rule match first, then what the classifier learned
+func (m *TransactionInputModel) suggest() {
+	desc := strings.TrimSpace(m.inputDesc.Value())
+	previous := ""
+	if len(m.suggestions) > 0 {
+		previous = m.suggestions[m.suggestion].name
+	}
+	m.suggestions = nil
+	m.suggestion = 0
+	if desc == "" {
+		return
+	}
 
-			fmt.Printf("Imported %d transactions successfully\n", len(imported))
-			return m, nil, path, nil
+	tx := models.Transaction{Payee: desc, Description: desc}
+	if amount, err := strconv.ParseFloat(m.inputAmount.Value(), 32); err == nil {
+		tx.Amount = float32(amount)
+	}
 
-		case tea.KeyEsc:
-			return m, nil, "", nil
+	seen := make(map[string]bool)
+	if found, err := db.MatchMerchant(desc); err == nil && found != nil {
+		tx.Payee = found.Name
+		if found.Category != nil {
+			m.suggestions = append(m.suggestions, categorySuggestion{name: found.Category.Name, source: "merchant"})
+			seen[found.Category.Name] = true
 		}
 	}
+	if res, err := db.ApplyRules(tx); err == nil && res.Transaction.Category.Name != "" {
+		name := res.Transaction.Category.Name
+		m.suggestions = append(m.suggestions, categorySuggestion{name: name, source: "rule"})
+		seen[name] = true
+	}
+	learned, err := db.SuggestCategories(tx, suggestionCount)
+	if err != nil {
+		return
+	}
+	for _, s := range learned {
+		if seen[s.Category] {
+			continue
+		}
+		m.suggestions = append(m.suggestions, categorySuggestion{
+			name:   s.Category,
+			source: fmt.Sprintf("%.0f%%", s.Confidence*100),
+		})
+	}
 
-	var cmd tea.Cmd
-	m.input, cmd = m.input.Update(msg)
-	return m, cmd, "", nil
+	// keep the highlight on the same category while typing
+	for i, s := range m.suggestions {
+		if s.name == previous {
+			m.suggestion = i
+		}
+	}
 }
 
 func (m *TransactionInputModel) updateFocus() {
@@ -290,10 +532,6 @@ func (m *TransactionInputModel) submit() (
 ) {
 
 	category := m.inputCategory.Value()
-	if category == "" {
-		m.errMsg = "Category cannot be empty"
-		return m, nil, nil, nil
-	}
 
 	amount, err := strconv.ParseFloat(m.inputAmount.Value(), 32)
 	if err != nil {
@@ -309,6 +547,7 @@ func (m *TransactionInputModel) submit() (
 
 	tx, err := db.CreateTransaction(
 		category,
+		m.inputDesc.Value(),
 		float32(amount),
 		dateStr,
 	)
@@ -328,6 +567,8 @@ func (m *TransactionInputModel) reset() {
 	m.inputAmount.SetValue("")
 	m.inputDate.SetValue("")
 	m.inputDesc.SetValue("")
+	m.suggestions = nil
+	m.suggestion = 0
 	m.focusIndex = 0
 	m.updateFocus()
 }
@@ -359,11 +600,16 @@ func (m *TransactionInputModel) View() string {
 
 	view += renderInput(m.inputDesc, m.focusIndex == 0) + "\n"
 
-	if m.recommendedCategory != "" {
-		view += fmt.Sprintf(
-			"Suggested category: %s (Tab to complete the Category with this recommandation)\n\n",
-			m.recommendedCategory,
-		)
+	if len(m.suggestions) > 0 {
+		view += "Suggested categories ([↑/↓] choose, [Tab] complete the Category):\n"
+		for i, s := range m.suggestions {
+			cursor := "  "
+			if i == m.suggestion {
+				cursor = "> "
+			}
+			view += fmt.Sprintf("%s%s (%s)\n", cursor, s.name, s.source)
+		}
+		view += "\n"
 	}
 
 	view += renderInput(m.inputCategory, m.focusIndex == 1) + "\n"
@@ -374,17 +620,6 @@ func (m *TransactionInputModel) View() string {
 	return view
 }
 
-func (m *FileInputModel) View() string {
-	view := ""
-	if m.errMsg != "" {
-		view += errorStyle.Render("❌ " + m.errMsg)
-		view += "\n\n"
-	}
-	view += renderInput(m.input, m.focus)
-	view += "\n\n[Enter] Import • [Esc] Back"
-	return view
-}
-
 func renderInput(input textinput.Model, focused bool) string {
 	if focused {
 		return focusedStyle.Render(input.View())
@@ -401,7 +636,7 @@ func (m *FilterTransactionsModel) View() string {
 	view += fmt.Sprintf("Value: %s\n", m.input.Value())
 
 	// <-- Add instructions here
-	view += "\n[Enter] Apply Filter • [b] Back • [f] Change Filter Mode\n\n"
+	view += "\n[Enter] Apply Filter • [b] Back • [f] Change Filter Mode • [e] Export results\n\n"
 	if len(m.filtered) > 0 {
 		for _, tx := range m.filtered {
 			sign := "+"
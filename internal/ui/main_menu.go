@@ -1,10 +1,14 @@
 package ui
 
 import (
+	"context"
 	_ "encoding/csv"
+	"errors"
 	"fmt"
 	_ "os"
+	"peronal_finance_cli_manager/internal/budget"
 	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/importer"
 	"peronal_finance_cli_manager/internal/models"
 	"peronal_finance_cli_manager/internal/transaction"
 	"strconv"
@@ -29,19 +33,29 @@ const (
 	StateBudgetOverview
 	StateMonthlyExpenseChart
 	StateUpdateCategory
+	StateDuplicateReview
+	StateImportPreview
+	StateImportHistory
+	StateExport
+	StateRules
+	StateInbox
+	StateMerchants
+	StateRecategorize
+	StateGoals
 )
 
 type FilterTransactionsModel struct {
 	input        textinput.Model
 	transactions []models.Transaction
 	filtered     []models.Transaction
-	mode         string // "date", "beforeDate", "year"
+	mode         string // "date", "beforeDate", "year", "range"
 	modes        []string
 	errMsg       string
 }
 
 type MenuModel struct {
 	list                  list.Model
+	height                int
 	inputModel            *InputModel
 	transactionInputModel *TransactionInputModel
 	state                 state
@@ -51,21 +65,44 @@ type MenuModel struct {
 
 	editingCategory *models.Category
 
-	importInput textinput.Model
-	importMsg   string
+	importInput   textinput.Model
+	importMsg     string
+	importProfile string
+	importJob     *importJob
+	importPreview *ImportPreviewModel
+	importHistory *ImportHistoryModel
 
 	filterModel *FilterTransactionsModel
 
+	duplicateModel *DuplicateReviewModel
+
+	exportModel  *ExportModel
+	exportReturn state
+
+	rulesModel *RulesModel
+	inboxModel *InboxModel
+
+	recategorizeModel *RecategorizeModel
+	merchantsModel    *MerchantsModel
+	goalsModel        *GoalsModel
+
 	monthInput textinput.Model
 	chartMsg   string
 
+	// budgetOffset is the budget period shown, relative to the current one
+	budgetOffset int
+	moveModel    *MoveMoneyModel
+	assignModel  *AssignBudgetModel
+	incomeModel  *ExpectedIncomeModel
+	budgetMsg    string
+
 	isUpdate bool
 }
 
 type CategoryItem models.Category
 
 func (c CategoryItem) Title() string {
-	return fmt.Sprintf("%s (Budget: %2.f)", c.Name, c.Budget)
+	return fmt.Sprintf("%s (Budget: %2.f %s)", c.Name, c.Budget, c.BudgetPeriod)
 }
 func (c CategoryItem) Description() string { return "" }
 func (c CategoryItem) FilterValue() string { return c.Name }
@@ -78,7 +115,7 @@ func NewMenuModel() *MenuModel {
 	l.SetFilteringEnabled(false)
 
 	ti := textinput.New()
-	ti.Placeholder = "Enter CSV file path..."
+	ti.Placeholder = "Enter CSV/OFX/QIF file path..."
 	ti.CharLimit = 256
 	ti.Focus()
 
@@ -92,6 +129,7 @@ func NewMenuModel() *MenuModel {
 		inputModel:            NewInputModelPtr(),
 		transactionInputModel: NewTransactionInputModel(),
 		importInput:           ti,
+		importProfile:         importer.DefaultProfile.Name,
 		state:                 StateList,
 		monthInput:            monthTi,
 	}
@@ -102,7 +140,7 @@ func NewFilterTransactionsModel(txs []models.Transaction, mode string) *FilterTr
 	ti.Placeholder = "Enter filter value"
 	ti.Focus()
 
-	modes := []string{"date", "beforeDate", "year"}
+	modes := []string{"date", "beforeDate", "year", "range"}
 
 	return &FilterTransactionsModel{
 		input:        ti,
@@ -150,6 +188,15 @@ func (m *FilterTransactionsModel) Update(msg tea.Msg) (*FilterTransactionsModel,
 				}
 				m.filtered = transaction.FilterByYear(m.transactions, y)
 
+			case "range":
+				fromStr, toStr, _ := strings.Cut(value, "..")
+				from, errFrom := time.Parse("2006-01-02", strings.TrimSpace(fromStr))
+				to, errTo := time.Parse("2006-01-02", strings.TrimSpace(toStr))
+				if errFrom != nil || errTo != nil {
+					m.errMsg = "Invalid range (YYYY-MM-DD..YYYY-MM-DD)"
+					return m, nil
+				}
+				m.filtered = transaction.FilterBetween(m.transactions, from, to)
 			}
 
 			return m, nil
@@ -193,6 +240,10 @@ func (m *MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.list.SetSize(msg.Width, msg.Height-4)
+		m.height = msg.Height
+		if m.importPreview != nil {
+			m.importPreview.SetHeight(msg.Height)
+		}
 		return m, nil
 	}
 
@@ -248,6 +299,32 @@ func (m *MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = StateMonthlyExpenseChart
 				return m, nil
 
+			case "d":
+				m.duplicateModel = NewDuplicateReviewModel()
+				m.state = StateDuplicateReview
+				return m, nil
+
+			case "r":
+				m.rulesModel = NewRulesModel()
+				m.state = StateRules
+				return m, nil
+			case "M":
+				m.merchantsModel = NewMerchantsModel()
+				m.state = StateMerchants
+				return m, nil
+			case "g":
+				m.goalsModel = NewGoalsModel()
+				m.state = StateGoals
+				return m, nil
+			case "u":
+				m.inboxModel = NewInboxModel()
+				m.state = StateInbox
+				return m, nil
+			case "h":
+				m.importHistory = NewImportHistoryModel()
+				m.state = StateImportHistory
+				return m, nil
+
 			}
 		}
 
@@ -303,13 +380,8 @@ func (m *MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cat := item.(CategoryItem)
 				m.editingCategory = (*models.Category)(&cat)
 
-				// configure budget input only
-				m.inputModel.inputBudget.SetValue(fmt.Sprintf("%.0f", cat.Budget))
-				m.inputModel.inputBudget.Focus()
-
-				// disable name input completely
-				m.inputModel.input.Blur()
-				m.inputModel.focusIndex = 1
+				// configure the budget inputs only
+				m.inputModel.startUpdate(*m.editingCategory)
 
 				m.state = StateUpdateCategory
 				return m, nil
@@ -353,11 +425,23 @@ func (m *MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Later we can add a dynamic selection menu for mode
 				m.filterModel = NewFilterTransactionsModel(m.transactions, "date")
 				m.state = StateFilterTransactions
+			case "e": // Export the category's transactions
+				m.exportModel = NewExportModel(m.selectedCategory.Name, m.transactions)
+				m.exportReturn = StateViewTransactions
+				m.state = StateExport
+				return m, textinput.Blink
 			}
 		}
 		return m, nil
 
 	case StateFilterTransactions:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "e" && len(m.filterModel.filtered) > 0 {
+			m.exportModel = NewExportModel(m.selectedCategory.Name+" (filtered)", m.filterModel.filtered)
+			m.exportReturn = StateFilterTransactions
+			m.state = StateExport
+			return m, textinput.Blink
+		}
+
 		var cmd tea.Cmd
 		m.filterModel, cmd = m.filterModel.Update(msg)
 		if keyMsg, ok := msg.(tea.KeyMsg); ok {
@@ -372,6 +456,45 @@ func (m *MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case StateImportCSV:
+		if m.importJob != nil {
+			switch msg := msg.(type) {
+			case importProgressMsg:
+				return m, m.importJob.progress(msg)
+
+			case importParsedMsg:
+				m.importJob = nil
+				if errors.Is(msg.err, context.Canceled) {
+					m.importMsg = "Import cancelled"
+					return m, nil
+				}
+				if msg.err != nil {
+					m.importMsg = "❌ Error parsing file: " + msg.err.Error()
+					return m, nil
+				}
+				m.importPreview = NewImportPreviewModel(msg, m.height)
+				m.state = StateImportPreview
+				return m, nil
+
+			case tea.KeyMsg:
+				if msg.Type == tea.KeyEsc {
+					m.importJob.cancel()
+				}
+			}
+			return m, nil
+		}
+
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Type == tea.KeyTab {
+			// cycle through the known import profiles
+			names := importer.ProfileNames()
+			for i, name := range names {
+				if name == m.importProfile {
+					m.importProfile = names[(i+1)%len(names)]
+					break
+				}
+			}
+			return m, nil
+		}
+
 		var cmd tea.Cmd
 		m.importInput, cmd = m.importInput.Update(msg)
 
@@ -380,47 +503,186 @@ func (m *MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "enter":
 
 				filePath := m.importInput.Value()
-				format := transaction.DetectFormat(filePath)
-				if format != "csv" {
-					m.importMsg = "❌ Unsupported format"
-					return m, nil
-				}
-
-				transactions, err := transaction.ParseCSV(filePath)
+				profile, err := importer.GetProfile(m.importProfile)
 				if err != nil {
-					m.importMsg = "❌ Error parsing CSV: " + err.Error()
+					m.importMsg = "❌ " + err.Error()
 					return m, nil
 				}
 
-				count := 0
-				for _, tx := range transactions {
-					// Check if category exists
-					cat, err := db.GetCategoryByName(tx.Category.Name)
-					if err != nil {
-						// If not exists, create
-						cat, err = db.CreateCategory(tx.Category.Name, 10000)
-						if err != nil {
-							continue // skip this transaction if category creation fails
-						}
-					}
+				// parse in the background so large files do not freeze the UI
+				m.importMsg = ""
+				m.importJob = startParseJob(filePath, profile)
+				return m, m.importJob.wait()
+			case "b":
+				m.state = StateList
+			}
+		}
 
-					// Create transaction
-					_, _ = db.CreateTransaction(cat.Name, tx.Amount, tx.Date.Format("2006-01-02"))
-					count++
-				}
+		return m, cmd
 
-				m.importMsg = fmt.Sprintf("✅ Imported %d transactions", count)
+	case StateRules:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && !m.rulesModel.Busy() {
+			switch keyMsg.String() {
 			case "b":
+				m.rulesModel = nil
 				m.state = StateList
+				return m, nil
+			case "c":
+				m.recategorizeModel = NewRecategorizeModel()
+				m.state = StateRecategorize
+				return m, textinput.Blink
 			}
 		}
+		var cmd tea.Cmd
+		m.rulesModel, cmd = m.rulesModel.Update(msg)
+		return m, cmd
 
+	case StateRecategorize:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "b" && !m.recategorizeModel.Busy() {
+			m.recategorizeModel = nil
+			m.state = StateRules
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.recategorizeModel, cmd = m.recategorizeModel.Update(msg)
 		return m, cmd
 
-	case StateBudgetOverview:
+	case StateMerchants:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "b" && !m.merchantsModel.Busy() {
+			m.merchantsModel = nil
+			m.state = StateList
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.merchantsModel, cmd = m.merchantsModel.Update(msg)
+		return m, cmd
+
+	case StateGoals:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "b" && !m.goalsModel.Busy() {
+			m.goalsModel = nil
+			m.state = StateList
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.goalsModel, cmd = m.goalsModel.Update(msg)
+		return m, cmd
+
+	case StateInbox:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "b" && !m.inboxModel.Busy() {
+			m.inboxModel = nil
+			m.state = StateList
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.inboxModel, cmd = m.inboxModel.Update(msg)
+		return m, cmd
+
+	case StateExport:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Type == tea.KeyEsc {
+			m.state = m.exportReturn
+			m.exportModel = nil
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.exportModel, cmd = m.exportModel.Update(msg)
+		return m, cmd
+
+	case StateImportPreview:
+		var cmd tea.Cmd
+		m.importPreview, cmd = m.importPreview.Update(msg)
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "b" && !m.importPreview.Busy() {
+			m.importPreview = nil
+			m.importInput.SetValue("")
+			m.state = StateList
+		}
+		return m, cmd
+
+	case StateImportHistory:
+		var cmd tea.Cmd
+		m.importHistory, cmd = m.importHistory.Update(msg)
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "b" {
+			m.importHistory = nil
 			m.state = StateList
 		}
+		return m, cmd
+
+	case StateBudgetOverview:
+		if m.moveModel != nil {
+			var cmd tea.Cmd
+			m.moveModel, cmd = m.moveModel.Update(msg)
+			if m.moveModel.Done {
+				m.budgetMsg = m.moveModel.Info
+				m.moveModel = nil
+			}
+			return m, cmd
+		}
+		if m.assignModel != nil {
+			var cmd tea.Cmd
+			m.assignModel, cmd = m.assignModel.Update(msg)
+			if m.assignModel.Done {
+				m.budgetMsg = m.assignModel.Info
+				m.assignModel = nil
+			}
+			return m, cmd
+		}
+		if m.incomeModel != nil {
+			var cmd tea.Cmd
+			m.incomeModel, cmd = m.incomeModel.Update(msg)
+			if m.incomeModel.Done {
+				m.budgetMsg = m.incomeModel.Info
+				m.incomeModel = nil
+			}
+			return m, cmd
+		}
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			m.budgetMsg = ""
+			switch keyMsg.String() {
+			case "m":
+				m.moveModel = NewMoveMoneyModel("")
+				return m, textinput.Blink
+			case "a":
+				if mode, _ := db.GetBudgetMode(); mode == budget.ModeZeroBased {
+					m.assignModel = NewAssignBudgetModel(m.budgetOffset)
+					return m, textinput.Blink
+				}
+			case "z":
+				mode := budget.ModeZeroBased
+				if current, _ := db.GetBudgetMode(); current == budget.ModeZeroBased {
+					mode = budget.ModeClassic
+				}
+				if err := db.SetBudgetMode(mode); err != nil {
+					m.budgetMsg = "❌ " + err.Error()
+				} else {
+					m.budgetMsg = "Budgeting mode: " + mode
+				}
+			case "p":
+				current, err := db.GetBudgetTemplate()
+				next := budget.NextTemplate(current.Name)
+				if err == nil {
+					err = db.SetBudgetTemplate(next)
+				}
+				switch {
+				case err != nil:
+					m.budgetMsg = "❌ " + err.Error()
+				case next == "":
+					m.budgetMsg = "No budget template"
+				default:
+					m.budgetMsg = "Budget template: " + next
+				}
+			case "i":
+				m.incomeModel = NewExpectedIncomeModel()
+				return m, textinput.Blink
+			case "b":
+				m.budgetOffset = 0
+				m.state = StateList
+			case "left", "h":
+				m.budgetOffset--
+			case "right", "l":
+				m.budgetOffset++
+			case "t":
+				m.budgetOffset = 0
+			}
+		}
 		return m, nil
 
 	case StateMonthlyExpenseChart:
@@ -453,31 +715,22 @@ func (m *MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.monthInput, cmd = m.monthInput.Update(msg)
 		return m, cmd
 
-	case StateUpdateCategory:
+	case StateDuplicateReview:
 		var cmd tea.Cmd
-		m.inputModel.inputBudget, cmd =
-			m.inputModel.inputBudget.Update(msg)
+		m.duplicateModel, cmd = m.duplicateModel.Update(msg)
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "b" {
+			m.duplicateModel = nil
+			m.state = StateList
+		}
+		return m, cmd
 
+	case StateUpdateCategory:
 		if keyMsg, ok := msg.(tea.KeyMsg); ok {
 			switch keyMsg.String() {
 
 			case "enter":
-				parsed, err := strconv.ParseFloat(
-					m.inputModel.inputBudget.Value(), 32,
-				)
-				if err != nil {
-					m.inputModel.errMsg = "Invalid budget"
-					return m, cmd
-				}
-
-				_ = db.UpdateCategoryBudget(
-					m.editingCategory.ID,
-					float32(parsed),
-				)
-
-				if err != nil {
-					m.inputModel.errMsg = err.Error()
-					return m, cmd
+				if !m.inputModel.submitBudget(m.editingCategory.ID) {
+					return m, nil
 				}
 
 				// 🔁 REFRESH CATEGORY LIST (THIS FIXES IT)
@@ -493,15 +746,17 @@ func (m *MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.inputModel.inputBudget.SetValue("")
 				m.editingCategory = nil
 				m.state = StateView
+				return m, nil
 
 			case "b":
 				m.inputModel.inputBudget.SetValue("")
 				m.editingCategory = nil
 				m.state = StateView
+				return m, nil
 			}
 		}
 
-		return m, cmd
+		return m, m.inputModel.updateBudget(msg)
 	}
 
 	return m, nil
@@ -512,7 +767,11 @@ func (m *MenuModel) View() string {
 
 	switch m.state {
 	case StateList:
-		return "[v] View Categories • [p] Budget overview • [a] Add category • [t] Add transaction • [m] Monthly Expense Chart • [i] Import CSV • [q] Quit"
+		inbox := "[u] Inbox"
+		if count, err := db.CountInbox(); err == nil && count > 0 {
+			inbox = fmt.Sprintf("[u] Inbox (%d)", count)
+		}
+		return "[v] View Categories • [p] Budget overview • [a] Add category • [t] Add transaction • [m] Monthly Expense Chart • [i] Import CSV • [h] Import history • [d] Duplicates • [r] Rules • [M] Merchants • [g] Goals • " + inbox + " • [q] Quit"
 
 	case StateAdd:
 		return fmt.Sprintf(
@@ -559,15 +818,19 @@ func (m *MenuModel) View() string {
 			)
 		}
 
-		view += "\n[b] Back • [f] Filter Transactions • [q] Quit"
+		view += "\n[b] Back • [f] Filter Transactions • [e] Export • [q] Quit"
 		return view
 
 	case StateImportCSV:
-		view := fmt.Sprintf("📥 Import CSV\n\n%s", m.importInput.View())
+		if m.importJob != nil {
+			return m.importJob.View()
+		}
+		view := fmt.Sprintf("📥 Import CSV / OFX / QIF\n\n%s", m.importInput.View())
+		view += fmt.Sprintf("\nProfile: %s", m.importProfile)
 		if m.importMsg != "" {
 			view += "\n\n" + m.importMsg
 		}
-		view += "\n\n[Enter] Import • [b] Back"
+		view += "\n\n[Enter] Preview • [Tab] Change profile • [b] Back"
 		return view
 
 	case StateFilterTransactions:
@@ -576,19 +839,47 @@ func (m *MenuModel) View() string {
 		}
 
 	case StateBudgetOverview:
-		stats, err := db.GetBudgetStats()
+		stats, err := db.GetBudgetStats(time.Now(), m.budgetOffset)
 		if err != nil {
 			return "❌ Failed to load budget stats\n\n[b] Back"
 		}
 
-		view := "📊 Budget Overview\n\n"
+		mode, err := db.GetBudgetMode()
+		if err != nil {
+			return "❌ Failed to load the budgeting mode\n\n[b] Back"
+		}
+
+		view := "📊 Budget Overview"
+		switch {
+		case m.budgetOffset < 0:
+			view += fmt.Sprintf(" (%d period(s) back)", -m.budgetOffset)
+		case m.budgetOffset > 0:
+			view += fmt.Sprintf(" (%d period(s) ahead)", m.budgetOffset)
+		}
+		view += "\n\n"
+
+		switch {
+		case strings.HasPrefix(m.budgetMsg, "❌"):
+			view += errorStyle.Render(m.budgetMsg) + "\n\n"
+		case m.budgetMsg != "":
+			view += greenStyle.Render(m.budgetMsg) + "\n\n"
+		}
+		if mode == budget.ModeZeroBased {
+			view += poolView(m.budgetOffset)
+		}
 
 		view += headerStyle.Render(
-			fmt.Sprintf("%-16s %7s / %-7s %6s   %s\n",
+			fmt.Sprintf("%-16s %-23s %7s %8s %7s %7s / %-7s %6s %9s %9s   %s\n",
 				"Category",
-				"Spent",
+				"Period",
 				"Budget",
+				"Carried",
+				"Moved",
+				"Spent",
+				"Avail",
 				"%",
+				"Inc%",
+				"Projected",
 				"Utilization"),
 		)
 		view += "\n"
@@ -601,7 +892,7 @@ func (m *MenuModel) View() string {
 			if s.Spent > maxSpent {
 				maxSpent = s.Spent
 			}
-			if strings.ToLower(s.CategoryName) == "income" {
+			if s.Kind == models.KindIncome {
 				totalIncome += s.Spent
 			} else {
 				totalExpense += s.Spent
@@ -611,20 +902,38 @@ func (m *MenuModel) View() string {
 		barWidth := 30 // max characters for the bar
 
 		for _, s := range stats {
-			percent := calculatePercentage(s.Spent, float32(s.Budget))
+			percent := calculatePercentage(s.Spent, float32(s.Available()))
 
+			carried := "-"
+			if s.Rollover != "" && s.Rollover != budget.RolloverNone {
+				carried = fmt.Sprintf("%.0f", s.Carried)
+			}
 			base := fmt.Sprintf(
-				"%-16s ",
+				"%-16s %-23s %7.0f %8s %7.0f ",
 				s.CategoryName,
+				s.PeriodLabel,
+				s.Budget,
+				carried,
+				s.Moved,
 			)
 
 			numbers := fmt.Sprintf(
 				"%7.0f / %-7.0f %6.0f%%",
 				s.Spent,
-				s.Budget,
+				s.Available(),
 				percent,
 			)
 
+			// the spending as a share of income, next to the budget
+			// when that is one
+			share := fmt.Sprintf(" %9s", fmt.Sprintf("%.0f%%", s.IncomeShare()))
+			if s.Percent > 0 {
+				share = fmt.Sprintf(" %9s", fmt.Sprintf("%.0f%%/%.0f%%", s.IncomeShare(), s.Percent))
+				if s.IncomeShare() > float64(s.Percent) {
+					share = redStyle.Render(share)
+				}
+			}
+
 			barPercent := s.Spent / maxSpent
 			filledWidth := int(barPercent * float32(barWidth))
 			if filledWidth < 1 {
@@ -636,14 +945,24 @@ func (m *MenuModel) View() string {
 
 			bar := strings.Repeat("─", filledWidth)
 
+			// the forecast for the period under way, red when it ends
+			// over the envelope
+			projected := fmt.Sprintf(" %9s", "-")
+			if s.HasProjection {
+				projected = fmt.Sprintf(" %9.0f", s.Projected)
+				if float64(s.Projected) > s.Available() {
+					projected = redStyle.Render(projected)
+				}
+			}
+
 			// color the numbers
 			switch {
 			case percent >= 100:
-				view += base + redStyle.Render(numbers) + "  " + redStyle.Render(bar) + "\n"
+				view += base + redStyle.Render(numbers) + share + projected + "  " + redStyle.Render(bar) + "\n"
 			case percent >= 80:
-				view += base + orangeStyle.Render(numbers) + "  " + orangeStyle.Render(bar) + "\n"
+				view += base + orangeStyle.Render(numbers) + share + projected + "  " + orangeStyle.Render(bar) + "\n"
 			default:
-				view += base + greenStyle.Render(numbers) + "  " + greenStyle.Render(bar) + "\n"
+				view += base + greenStyle.Render(numbers) + share + projected + "  " + greenStyle.Render(bar) + "\n"
 			}
 		}
 
@@ -679,7 +998,25 @@ func (m *MenuModel) View() string {
 		view += fmt.Sprintf("Expense  %s (%v)\n", expenseBar, totalExpense)
 		view += fmt.Sprintf("Income   %s (%v)\n", incomeBar, totalIncome)
 
-		view += "\n[b] Back"
+		view += templateView(m.budgetOffset, stats)
+		view += movesView(stats)
+
+		if m.moveModel != nil {
+			return view + "\n" + m.moveModel.View()
+		}
+		if m.assignModel != nil {
+			return view + "\n" + m.assignModel.View()
+		}
+		if m.incomeModel != nil {
+			return view + "\n" + m.incomeModel.View()
+		}
+		view += "\n[←/→] Previous/next period • [t] Current period • [m] Move money • [p] Template • [i] Expected income"
+		if mode == budget.ModeZeroBased {
+			view += " • [a] Assign • [z] Classic budgets"
+		} else {
+			view += " • [z] Zero-based budgeting"
+		}
+		view += " • [b] Back"
 		return view
 
 	case StateMonthlyExpenseChart:
@@ -691,6 +1028,51 @@ func (m *MenuModel) View() string {
 		view += "\n\n[Enter] Generate • [b] Back"
 		return view
 
+	case StateDuplicateReview:
+		if m.duplicateModel != nil {
+			return m.duplicateModel.View()
+		}
+
+	case StateImportPreview:
+		if m.importPreview != nil {
+			return m.importPreview.View()
+		}
+
+	case StateRules:
+		if m.rulesModel != nil {
+			return m.rulesModel.View()
+		}
+
+	case StateRecategorize:
+		if m.recategorizeModel != nil {
+			return m.recategorizeModel.View()
+		}
+
+	case StateMerchants:
+		if m.merchantsModel != nil {
+			return m.merchantsModel.View()
+		}
+
+	case StateGoals:
+		if m.goalsModel != nil {
+			return m.goalsModel.View()
+		}
+
+	case StateInbox:
+		if m.inboxModel != nil {
+			return m.inboxModel.View()
+		}
+
+	case StateExport:
+		if m.exportModel != nil {
+			return m.exportModel.View()
+		}
+
+	case StateImportHistory:
+		if m.importHistory != nil {
+			return m.importHistory.View()
+		}
+
 	case StateUpdateCategory:
 		view := fmt.Sprintf(
 			"✏️ Update Category %s\n\n",
@@ -701,12 +1083,18 @@ func (m *MenuModel) View() string {
 			view += errorStyle.Render("❌ "+m.inputModel.errMsg) + "\n\n"
 		}
 
-		view += renderInput(
-			m.inputModel.inputBudget,
-			true,
-		)
-
-		view += "\n\n[Enter] Save • [b] Back"
+		view += renderInput(m.inputModel.inputBudget, m.inputModel.focusIndex == 1) + "\n"
+		view += renderInput(m.inputModel.inputPeriod, m.inputModel.focusIndex == 2) + "\n"
+		view += renderInput(m.inputModel.inputAnchor, m.inputModel.focusIndex == 3) + "\n"
+		view += renderInput(m.inputModel.inputScope, m.inputModel.focusIndex == 4) + "\n"
+		view += renderInput(m.inputModel.inputRoll, m.inputModel.focusIndex == 5) + "\n"
+		view += renderInput(m.inputModel.inputKind, m.inputModel.focusIndex == 6) + "\n"
+		view += renderInput(m.inputModel.inputAlerts, m.inputModel.focusIndex == 7) + "\n"
+		view += renderInput(m.inputModel.inputGroup, m.inputModel.focusIndex == 8)
+		view += "\n\nA budget such as 15% is that share of the period's income."
+		view += budgetHistoryView(*m.editingCategory)
+
+		view += "\n\n[Tab] Next • [Enter] Save • [b] Back"
 		return view
 
 	}
@@ -0,0 +1,354 @@
+package ui
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/merchant"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// MerchantsModel manages the merchants with their alias patterns and
+// default categories, and merges the raw payees of stored transactions
+// into merchants
+type MerchantsModel struct {
+	merchants []models.Merchant
+	payees    []models.PayeeCount
+	selected  map[string]bool
+
+	// showPayees switches between the merchant and the payee list
+	showPayees bool
+	cursor     int
+	errMsg     string
+	info       string
+
+	// editing is set while a form is open: the merchant form (name,
+	// category, aliases) or, when merging, the merge form (name, category)
+	editing bool
+	merging bool
+	editID  uint
+	focus   int
+	inputs  []textinput.Model
+}
+
+func NewMerchantsModel() *MerchantsModel {
+	m := &MerchantsModel{selected: make(map[string]bool)}
+	m.load()
+	return m
+}
+
+func (m *MerchantsModel) load() {
+	merchants, err := db.GetMerchants()
+	if err != nil {
+		m.errMsg = "Failed to load merchants: " + err.Error()
+		return
+	}
+	payees, err := db.GetPayees()
+	if err != nil {
+		m.errMsg = "Failed to load payees: " + err.Error()
+		return
+	}
+	m.merchants = merchants
+	m.payees = payees
+	m.cursor = min(max(m.cursor, 0), max(m.size()-1, 0))
+}
+
+func (m *MerchantsModel) size() int {
+	if m.showPayees {
+		return len(m.payees)
+	}
+	return len(m.merchants)
+}
+
+// Busy reports whether a text field has the keyboard
+func (m *MerchantsModel) Busy() bool {
+	return m.editing
+}
+
+func (m *MerchantsModel) Update(msg tea.Msg) (*MerchantsModel, tea.Cmd) {
+	if m.editing {
+		return m.updateEdit(msg)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	m.info = ""
+
+	switch keyMsg.String() {
+	case "tab":
+		m.showPayees = !m.showPayees
+		m.cursor = 0
+		m.errMsg = ""
+		return m, nil
+
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+
+	case "down":
+		if m.cursor < m.size()-1 {
+			m.cursor++
+		}
+		return m, nil
+	}
+
+	if m.showPayees {
+		return m.updatePayees(keyMsg)
+	}
+
+	switch keyMsg.String() {
+	case "a":
+		m.startEdit(models.Merchant{})
+		return m, textinput.Blink
+
+	case "e":
+		if len(m.merchants) > 0 {
+			m.startEdit(m.merchants[m.cursor])
+			return m, textinput.Blink
+		}
+
+	case "x":
+		if len(m.merchants) == 0 {
+			return m, nil
+		}
+		if err := db.DeleteMerchant(m.merchants[m.cursor].ID); err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.load()
+
+	case "N":
+		renamed, err := db.NormalizePayees()
+		if err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.info = fmt.Sprintf("Renamed the payee of %d transaction(s)", renamed)
+		m.load()
+	}
+	return m, nil
+}
+
+func (m *MerchantsModel) updatePayees(keyMsg tea.KeyMsg) (*MerchantsModel, tea.Cmd) {
+	if len(m.payees) == 0 {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case " ":
+		payee := m.payees[m.cursor].Payee
+		if m.selected[payee] {
+			delete(m.selected, payee)
+		} else {
+			m.selected[payee] = true
+		}
+
+	case "m":
+		if len(m.selected) == 0 {
+			m.selected[m.payees[m.cursor].Payee] = true
+		}
+		m.startMerge()
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+func (m *MerchantsModel) startEdit(merchant models.Merchant) {
+	name := textinput.New()
+	name.Placeholder = "Merchant name, e.g. Uber"
+	name.SetValue(merchant.Name)
+
+	category := textinput.New()
+	category.Placeholder = "Default category (optional)"
+	if merchant.Category != nil {
+		category.SetValue(merchant.Category.Name)
+	}
+
+	aliases := textinput.New()
+	aliases.Placeholder = "Alias patterns separated by ';', e.g. ^uber\\b; uber\\s+bv"
+	patterns := make([]string, 0, len(merchant.Aliases))
+	for _, a := range merchant.Aliases {
+		patterns = append(patterns, a.Pattern)
+	}
+	aliases.SetValue(strings.Join(patterns, "; "))
+
+	m.open([]textinput.Model{name, category, aliases})
+	m.editID = merchant.ID
+	m.merging = false
+}
+
+func (m *MerchantsModel) startMerge() {
+	name := textinput.New()
+	name.Placeholder = "Merchant name (existing or new)"
+	name.SetValue(merchant.Clean(m.payees[m.cursor].Payee))
+
+	category := textinput.New()
+	category.Placeholder = "Default category (optional)"
+
+	m.open([]textinput.Model{name, category})
+	m.merging = true
+}
+
+func (m *MerchantsModel) open(inputs []textinput.Model) {
+	m.inputs = inputs
+	m.focus = 0
+	m.inputs[0].Focus()
+	m.editing = true
+	m.errMsg = ""
+}
+
+func (m *MerchantsModel) updateEdit(msg tea.Msg) (*MerchantsModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyTab:
+			m.inputs[m.focus].Blur()
+			m.focus = (m.focus + 1) % len(m.inputs)
+			m.inputs[m.focus].Focus()
+			return m, nil
+
+		case tea.KeyEsc:
+			m.editing = false
+			m.errMsg = ""
+			return m, nil
+
+		case tea.KeyEnter:
+			if m.merging {
+				m.merge()
+			} else {
+				m.save()
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+func (m *MerchantsModel) save() {
+	merchant := models.Merchant{ID: m.editID, Name: m.inputs[0].Value()}
+	patterns := strings.Split(m.inputs[2].Value(), ";")
+	if err := db.SaveMerchant(&merchant, strings.TrimSpace(m.inputs[1].Value()), patterns); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.editing = false
+	m.errMsg = ""
+	m.load()
+	for i, other := range m.merchants {
+		if other.ID == merchant.ID {
+			m.cursor = i
+		}
+	}
+}
+
+func (m *MerchantsModel) merge() {
+	payees := make([]string, 0, len(m.selected))
+	for payee := range m.selected {
+		payees = append(payees, payee)
+	}
+	name := strings.TrimSpace(m.inputs[0].Value())
+	renamed, err := db.MergePayees(payees, name, strings.TrimSpace(m.inputs[1].Value()))
+	if err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.editing = false
+	m.errMsg = ""
+	m.info = fmt.Sprintf("Merged %d payee(s) into %s, %d transaction(s) renamed", len(payees), name, renamed)
+	m.selected = make(map[string]bool)
+	m.load()
+}
+
+func (m *MerchantsModel) View() string {
+	view := "🏪 Merchants\n\n"
+	if m.showPayees {
+		view = "🏪 Payees\n\n"
+	}
+
+	if m.errMsg != "" {
+		view += errorStyle.Render("❌ "+m.errMsg) + "\n\n"
+	} else if m.info != "" {
+		view += greenStyle.Render(m.info) + "\n\n"
+	}
+
+	if m.showPayees {
+		view += m.payeesView()
+	} else {
+		view += m.merchantsView()
+	}
+
+	if m.editing {
+		title := "Add merchant"
+		switch {
+		case m.merging:
+			title = fmt.Sprintf("Merge %d payee(s) into a merchant", len(m.selected))
+		case m.editID != 0:
+			title = "Edit merchant"
+		}
+		view += fmt.Sprintf("\n✏️ %s\n\n", title)
+		for i, input := range m.inputs {
+			view += renderInput(input, i == m.focus) + "\n"
+		}
+		return view + "\n[Tab] Next • [Enter] Save • [Esc] Cancel"
+	}
+
+	if m.showPayees {
+		return view + "\n[↑/↓] Move • [Space] Select • [m] Merge into merchant • [Tab] Merchants • [b] Back"
+	}
+	return view + "\n[↑/↓] Move • [a] Add • [e] Edit • [x] Delete • [N] Normalize stored payees • [Tab] Payees • [b] Back"
+}
+
+func (m *MerchantsModel) merchantsView() string {
+	if len(m.merchants) == 0 {
+		return "No merchants yet. Merge payees from the payee list or add one.\n"
+	}
+	view := headerStyle.Render(fmt.Sprintf("  %-24s %-16s %s", "Merchant", "Category", "Aliases")) + "\n"
+	for i, merchant := range m.merchants {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		category := ""
+		if merchant.Category != nil {
+			category = merchant.Category.Name
+		}
+		patterns := make([]string, 0, len(merchant.Aliases))
+		for _, a := range merchant.Aliases {
+			patterns = append(patterns, a.Pattern)
+		}
+		view += fmt.Sprintf("%s%-24s %-16s %s\n", cursor, merchant.Name, category, strings.Join(patterns, "; "))
+	}
+	return view
+}
+
+func (m *MerchantsModel) payeesView() string {
+	if len(m.payees) == 0 {
+		return "No payees yet.\n"
+	}
+	view := headerStyle.Render(fmt.Sprintf("  %-3s %-40s %s", "", "Payee", "Transactions")) + "\n"
+	for i, p := range m.payees {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		mark := "[ ]"
+		if m.selected[p.Payee] {
+			mark = "[x]"
+		}
+		view += fmt.Sprintf("%s%-3s %-40s %d\n", cursor, mark, p.Payee, p.Count)
+	}
+	return view
+}
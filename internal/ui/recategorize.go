@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RecategorizeModel runs the current rules over stored transactions, shows
+// which ones would move to another category and applies the selected moves
+type RecategorizeModel struct {
+	// scoping is set while the scope form is open
+	scoping bool
+	focus   int
+	inputs  []textinput.Model // from, to, category
+
+	moves    []models.CategoryMove
+	selected []bool
+	cursor   int
+
+	errMsg string
+	info   string
+}
+
+func NewRecategorizeModel() *RecategorizeModel {
+	from := textinput.New()
+	from.Placeholder = "From (YYYY-MM-DD, empty = any)"
+	to := textinput.New()
+	to.Placeholder = "To (YYYY-MM-DD, empty = any)"
+	category := textinput.New()
+	category.Placeholder = "Only this category (empty = all)"
+
+	m := &RecategorizeModel{inputs: []textinput.Model{from, to, category}}
+	m.startScope()
+	return m
+}
+
+// Busy reports whether a text field has the keyboard
+func (m *RecategorizeModel) Busy() bool {
+	return m.scoping
+}
+
+func (m *RecategorizeModel) startScope() {
+	m.scoping = true
+	m.focus = 0
+	for i := range m.inputs {
+		m.inputs[i].Blur()
+	}
+	m.inputs[0].Focus()
+}
+
+func (m *RecategorizeModel) Update(msg tea.Msg) (*RecategorizeModel, tea.Cmd) {
+	if m.scoping {
+		return m.updateScope(msg)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "s":
+		m.startScope()
+		return m, textinput.Blink
+
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down":
+		if m.cursor < len(m.moves)-1 {
+			m.cursor++
+		}
+
+	case " ":
+		if len(m.moves) > 0 {
+			m.selected[m.cursor] = !m.selected[m.cursor]
+		}
+
+	case "n": // select none, or all again
+		all := !m.anySelected()
+		for i := range m.selected {
+			m.selected[i] = all
+		}
+
+	case "a":
+		m.apply()
+	}
+
+	return m, nil
+}
+
+func (m *RecategorizeModel) updateScope(msg tea.Msg) (*RecategorizeModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyTab:
+			m.inputs[m.focus].Blur()
+			m.focus = (m.focus + 1) % len(m.inputs)
+			m.inputs[m.focus].Focus()
+			return m, nil
+
+		case tea.KeyEsc:
+			m.scoping = false
+			m.inputs[m.focus].Blur()
+			return m, nil
+
+		case tea.KeyEnter:
+			m.plan()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+// plan evaluates the rules for the scope in the form
+func (m *RecategorizeModel) plan() {
+	var scope db.RecategorizeScope
+	for i, date := range []*time.Time{&scope.From, &scope.To} {
+		value := strings.TrimSpace(m.inputs[i].Value())
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			m.errMsg = fmt.Sprintf("Invalid date '%s' (YYYY-MM-DD)", value)
+			return
+		}
+		*date = parsed
+	}
+	scope.CategoryName = strings.TrimSpace(m.inputs[2].Value())
+
+	moves, err := db.PlanRecategorization(scope)
+	if err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.moves = moves
+	m.selected = make([]bool, len(moves))
+	for i := range m.selected {
+		m.selected[i] = true
+	}
+	m.cursor = 0
+	m.scoping = false
+	m.inputs[m.focus].Blur()
+	m.errMsg = ""
+	m.info = ""
+}
+
+func (m *RecategorizeModel) anySelected() bool {
+	for _, s := range m.selected {
+		if s {
+			return true
+		}
+	}
+	return false
+}
+
+// apply moves the selected transactions and plans again, so the list
+// shows what is still left
+func (m *RecategorizeModel) apply() {
+	var moves []models.CategoryMove
+	for i, move := range m.moves {
+		if m.selected[i] {
+			moves = append(moves, move)
+		}
+	}
+	if len(moves) == 0 {
+		m.errMsg = "No transaction selected"
+		return
+	}
+
+	moved, err := db.ApplyRecategorization(moves)
+	if err != nil {
+		m.errMsg = "Nothing moved: " + err.Error()
+		return
+	}
+	m.plan()
+	m.info = fmt.Sprintf("Moved %d transaction(s), recorded in the audit log", moved)
+}
+
+func (m *RecategorizeModel) View() string {
+	view := "🔁 Re-categorize with the current rules\n\n"
+
+	if m.errMsg != "" {
+		view += errorStyle.Render("❌ "+m.errMsg) + "\n\n"
+	} else if m.info != "" {
+		view += greenStyle.Render(m.info) + "\n\n"
+	}
+
+	if m.scoping {
+		for i, input := range m.inputs {
+			view += renderInput(input, i == m.focus) + "\n"
+		}
+		return view + "\n[Tab] Next • [Enter] Preview • [Esc] Cancel"
+	}
+
+	if len(m.moves) == 0 {
+		return view + "The rules would not move any transaction.\n\n[s] Change scope • [b] Back"
+	}
+
+	view += headerStyle.Render(fmt.Sprintf("  %-3s %-10s %10s  %-24s %-16s    %s",
+		"", "Date", "Amount", "Payee", "From", "To"))
+	view += "\n"
+	for i, move := range m.moves {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		mark := "[ ]"
+		if m.selected[i] {
+			mark = "[x]"
+		}
+		tx := move.Transaction
+		line := fmt.Sprintf("%s%-3s %-10s %10.2f  %-24s %-16s",
+			cursor, mark, tx.Date.Format("2006-01-02"), tx.Amount, tx.Payee, move.From.Name)
+		view += line + " →  " + greenStyle.Render(move.To.Name) + "\n"
+	}
+
+	view += "\n[↑/↓] Move • [Space] Select • [n] None/All • [a] Apply selected • [s] Change scope • [b] Back"
+	return view
+}
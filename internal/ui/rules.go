@@ -0,0 +1,642 @@
+package ui
+
+import (
+	"fmt"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"peronal_finance_cli_manager/internal/rules"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Rule form fields, in the order of editInputs
+const (
+	ruleName = iota
+	rulePattern
+	ruleCategory
+	ruleMatch
+	ruleConditions
+	ruleActions
+	rulePriority
+	ruleStop
+)
+
+// RulesModel lists the categorization rules and lets them be added,
+// edited, reordered, switched off, tried out on a test bench, checked
+// against the history
+type RulesModel struct {
+	rules  []models.CategoryRule
+	cursor int
+	errMsg string
+	info   string
+
+	// editing is set while the rule form is open; editID is 0 for a new rule
+	editing    bool
+	editID     uint
+	editFocus  int
+	editInputs []textinput.Model
+
+	testing    bool
+	testFocus  int
+	testInputs []textinput.Model // text or #id, amount
+	// testOutput is the test bench result, worked out again only when
+	// the inputs change, as it reads the database
+	testOutput string
+
+	// report is set while the history report is shown
+	report []rules.Coverage
+
+	// deleting is set while the deletion of the rule under the cursor
+	// waits for confirmation
+	deleting bool
+}
+
+func NewRulesModel() *RulesModel {
+	text := textinput.New()
+	text.Placeholder = "Payee or description to test, or #id of a transaction"
+
+	amount := textinput.New()
+	amount.Placeholder = "Amount (optional)"
+
+	m := &RulesModel{testInputs: []textinput.Model{text, amount}}
+	m.load()
+	return m
+}
+
+func (m *RulesModel) load() {
+	rules, err := db.GetCategoryRules()
+	if err != nil {
+		m.errMsg = "Failed to load rules: " + err.Error()
+		return
+	}
+	m.rules = rules
+	if m.cursor >= len(m.rules) {
+		m.cursor = len(m.rules) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// Busy reports whether a text field or a confirmation has the keyboard
+func (m *RulesModel) Busy() bool {
+	return m.editing || m.testing || m.deleting
+}
+
+func (m *RulesModel) Update(msg tea.Msg) (*RulesModel, tea.Cmd) {
+	if m.editing {
+		return m.updateEdit(msg)
+	}
+	if m.testing {
+		return m.updateTest(msg)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	m.info = ""
+	if m.deleting {
+		m.deleting = false
+		if keyMsg.String() != "y" {
+			return m, nil
+		}
+		rule := m.rules[m.cursor]
+		if err := db.DeleteCategoryRule(rule.ID); err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.info = "Deleted rule " + ruleTitle(rule)
+		m.load()
+		return m, nil
+	}
+	if m.report != nil {
+		if keyMsg.String() == "esc" || keyMsg.String() == "p" {
+			m.report = nil
+		}
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "p":
+		report, err := db.AnalyzeRules()
+		if err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.report = report
+		return m, nil
+
+	case "a":
+		m.startEdit(models.CategoryRule{})
+		return m, textinput.Blink
+
+	case "t":
+		m.testing = true
+		m.testFocus = 0
+		m.testOutput = ""
+		for i := range m.testInputs {
+			m.testInputs[i].SetValue("")
+			m.testInputs[i].Blur()
+		}
+		m.testInputs[0].Focus()
+		return m, textinput.Blink
+	}
+
+	if len(m.rules) == 0 {
+		return m, nil
+	}
+	rule := m.rules[m.cursor]
+
+	switch keyMsg.String() {
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down":
+		if m.cursor < len(m.rules)-1 {
+			m.cursor++
+		}
+
+	case "shift+up", "K":
+		m.move(rule.ID, -1)
+
+	case "shift+down", "J":
+		m.move(rule.ID, 1)
+
+	case "e":
+		m.startEdit(rule)
+		return m, textinput.Blink
+
+	case " ":
+		if err := db.SetCategoryRuleEnabled(rule.ID, !rule.Enabled); err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.errMsg = ""
+		m.load()
+
+	case "x":
+		m.errMsg = ""
+		m.deleting = true
+	}
+
+	return m, nil
+}
+
+// ruleTitle names a rule by its name, or its position when it has none
+func ruleTitle(rule models.CategoryRule) string {
+	if rule.Name != "" {
+		return "'" + rule.Name + "'"
+	}
+	return fmt.Sprintf("#%d", rule.Priority)
+}
+
+func (m *RulesModel) move(id uint, delta int) {
+	if err := db.MoveCategoryRule(id, delta); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+	m.errMsg = ""
+	m.load()
+	for i, rule := range m.rules {
+		if rule.ID == id {
+			m.cursor = i
+		}
+	}
+}
+
+func (m *RulesModel) startEdit(rule models.CategoryRule) {
+	field := func(placeholder, value string) textinput.Model {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.SetValue(value)
+		return input
+	}
+
+	category := ""
+	if rule.Category != nil {
+		category = rule.Category.Name
+	}
+	match := rule.Match
+	if match == "" {
+		match = models.MatchAll
+	}
+	priority := ""
+	if rule.ID != 0 {
+		priority = strconv.Itoa(rule.Priority)
+	}
+	stop := "no"
+	if rule.Stop {
+		stop = "yes"
+	}
+
+	m.editInputs = []textinput.Model{
+		ruleName:       field("Name (optional)", rule.Name),
+		rulePattern:    field("Pattern (regular expression on payee or description, e.g. (?i)uber|bolt)", rule.Pattern),
+		ruleCategory:   field("Category Name (optional)", category),
+		ruleMatch:      field("Match conditions: all or any", match),
+		ruleConditions: field("Conditions, e.g. amount between -50..-10; account equals Visa", rules.FormatConditions(rule.Conditions)),
+		ruleActions:    field("Actions, e.g. tag travel; payee Uber; transfer; split Food=60,Household=40", rules.FormatActions(rule.Actions)),
+		rulePriority:   field("Priority (empty = last)", priority),
+		ruleStop:       field("Stop after this rule: yes or no", stop),
+	}
+	m.editID = rule.ID
+	m.editFocus = 0
+	m.editInputs[0].Focus()
+	m.editing = true
+	m.errMsg = ""
+}
+
+func (m *RulesModel) updateEdit(msg tea.Msg) (*RulesModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyTab, tea.KeyShiftTab:
+			step := 1
+			if keyMsg.Type == tea.KeyShiftTab {
+				step = len(m.editInputs) - 1
+			}
+			m.editInputs[m.editFocus].Blur()
+			m.editFocus = (m.editFocus + step) % len(m.editInputs)
+			m.editInputs[m.editFocus].Focus()
+			return m, nil
+
+		case tea.KeyEsc:
+			m.editing = false
+			m.errMsg = ""
+			return m, nil
+
+		case tea.KeyEnter:
+			m.saveEdit()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.editInputs[m.editFocus], cmd = m.editInputs[m.editFocus].Update(msg)
+	return m, cmd
+}
+
+func (m *RulesModel) saveEdit() {
+	value := func(field int) string {
+		return strings.TrimSpace(m.editInputs[field].Value())
+	}
+
+	rule := models.CategoryRule{
+		ID:      m.editID,
+		Name:    value(ruleName),
+		Pattern: value(rulePattern),
+		Match:   strings.ToLower(value(ruleMatch)),
+		Enabled: true,
+	}
+	for _, r := range m.rules {
+		if r.ID == m.editID {
+			rule.Enabled = r.Enabled
+		}
+	}
+
+	var err error
+	if rule.Conditions, err = rules.ParseConditions(value(ruleConditions)); err != nil {
+		m.errMsg = "Conditions: " + err.Error()
+		return
+	}
+	if rule.Actions, err = rules.ParseActions(value(ruleActions)); err != nil {
+		m.errMsg = "Actions: " + err.Error()
+		return
+	}
+	if rule.Pattern == "" && len(rule.Conditions) == 0 {
+		m.errMsg = "A rule needs a pattern or conditions"
+		return
+	}
+	if value(ruleCategory) == "" && len(rule.Actions) == 0 {
+		m.errMsg = "A rule needs a category or actions"
+		return
+	}
+	switch strings.ToLower(value(ruleStop)) {
+	case "yes", "y", "true":
+		rule.Stop = true
+	case "", "no", "n", "false":
+	default:
+		m.errMsg = "Stop must be yes or no"
+		return
+	}
+	if value := value(rulePriority); value != "" {
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			m.errMsg = "Priority must be a whole number"
+			return
+		}
+		rule.Priority = priority
+	}
+
+	if err := db.SaveCategoryRule(&rule, value(ruleCategory)); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.editing = false
+	m.errMsg = ""
+	m.load()
+	for i, r := range m.rules {
+		if r.ID == rule.ID {
+			m.cursor = i
+		}
+	}
+}
+
+func (m *RulesModel) updateTest(msg tea.Msg) (*RulesModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.testing = false
+			m.testInputs[m.testFocus].Blur()
+			return m, nil
+
+		case tea.KeyTab:
+			m.testInputs[m.testFocus].Blur()
+			m.testFocus = (m.testFocus + 1) % len(m.testInputs)
+			m.testInputs[m.testFocus].Focus()
+			return m, nil
+		}
+	}
+
+	before := m.testInputs[m.testFocus].Value()
+	var cmd tea.Cmd
+	m.testInputs[m.testFocus], cmd = m.testInputs[m.testFocus].Update(msg)
+	if m.testInputs[m.testFocus].Value() != before {
+		m.testOutput = m.testResult()
+	}
+	return m, cmd
+}
+
+// testTransaction is the transaction typed on the test bench, or the
+// stored one picked with #id
+func (m *RulesModel) testTransaction() (models.Transaction, error) {
+	text := strings.TrimSpace(m.testInputs[0].Value())
+	tx := models.Transaction{Payee: text, Description: text}
+	if value, ok := strings.CutPrefix(text, "#"); ok {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return tx, fmt.Errorf("invalid transaction id '%s'", value)
+		}
+		stored, err := db.GetTransaction(uint(id))
+		if err != nil {
+			return tx, fmt.Errorf("transaction #%d not found", id)
+		}
+		tx = *stored
+	}
+	if value := strings.TrimSpace(m.testInputs[1].Value()); value != "" {
+		amount, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return tx, fmt.Errorf("invalid amount")
+		}
+		tx.Amount = float32(amount)
+	}
+	return tx, nil
+}
+
+// testResult walks through every rule in priority order for the test
+// transaction and ends with what the rules would do to it
+func (m *RulesModel) testResult() string {
+	if strings.TrimSpace(m.testInputs[0].Value()) == "" {
+		return ""
+	}
+	tx, err := m.testTransaction()
+	if err != nil {
+		return errorStyle.Render("❌ " + err.Error())
+	}
+	steps, res, err := db.ExplainRules(tx)
+	if err != nil {
+		return errorStyle.Render("❌ " + err.Error())
+	}
+
+	view := ""
+	if tx.ID != 0 {
+		view += fmt.Sprintf("#%d %s %10.2f  %s (%s)\n\n",
+			tx.ID, tx.Date.Format("2006-01-02"), tx.Amount, tx.Payee, tx.Category.Name)
+	}
+	for _, step := range steps {
+		view += m.describeStep(step) + "\n"
+	}
+	if len(res.Matched) == 0 {
+		return view + "\n" + orangeStyle.Render("No rule matches")
+	}
+	return view + "\n" + greenStyle.Render(describeResult(res, m.rules))
+}
+
+// describeStep is one line of the test bench: how a rule fared
+func (m *RulesModel) describeStep(step rules.Step) string {
+	line := fmt.Sprintf("%-4d %-16s ", step.Rule.Priority, ruleLabel(step.Rule))
+	switch {
+	case step.Err != nil:
+		return redStyle.Render(line + "broken: " + step.Err.Error())
+	case !step.Matched && !step.Rule.Enabled:
+		return orangeStyle.Render(line + "disabled")
+	case !step.Matched:
+		return line + "no match"
+	case !step.Rule.Enabled:
+		return orangeStyle.Render(line + "disabled, would match")
+	case step.StoppedBy != 0:
+		return orangeStyle.Render(line + "matches, not reached: stopped by " + m.labels(step.StoppedBy))
+	}
+
+	line += "matched"
+	if len(step.Groups) > 0 {
+		line += " [" + strings.Join(step.Groups, " ") + "]"
+	}
+	if len(step.Effects) > 0 {
+		line += " → " + strings.Join(step.Effects, "; ")
+	}
+	if len(step.DecidedBy) > 0 {
+		line += " (rest decided by " + m.labels(step.DecidedBy...) + ")"
+	}
+	if len(step.Effects) == 0 {
+		return orangeStyle.Render(line + ", no effect")
+	}
+	return greenStyle.Render(line)
+}
+
+// labels names the rules with the given ids
+func (m *RulesModel) labels(ids ...uint) string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		for _, rule := range m.rules {
+			if rule.ID == id {
+				names = append(names, ruleLabel(rule))
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// reportView lists how often each enabled rule matched and changed the
+// stored transactions, flagging the rules that never match and those
+// shadowed by higher-priority rules
+func (m *RulesModel) reportView() string {
+	if len(m.report) == 0 {
+		return "No enabled rules.\n"
+	}
+	view := headerStyle.Render(fmt.Sprintf("  %-4s %-16s %8s %8s  %s", "Prio", "Name", "Matched", "Changed", "Finding")) + "\n"
+	for _, c := range m.report {
+		line := fmt.Sprintf("  %-4d %-16s %8d %8d  ", c.Rule.Priority, ruleLabel(c.Rule), c.Matched, c.Effective)
+		switch {
+		case c.Never():
+			view += orangeStyle.Render(line+"never matches") + "\n"
+		case c.Shadowed():
+			view += orangeStyle.Render(line+"shadowed by "+m.labels(c.ShadowedBy...)) + "\n"
+		case len(c.ShadowedBy) > 0:
+			view += line + "partly shadowed by " + m.labels(c.ShadowedBy...) + "\n"
+		default:
+			view += line + "\n"
+		}
+	}
+	return view
+}
+
+// describeResult lists what the rules changed and which rules matched
+func describeResult(res rules.Result, list []models.CategoryRule) string {
+	var lines []string
+	out := res.Transaction
+	if out.Category.Name != "" {
+		lines = append(lines, "→ category "+out.Category.Name)
+	}
+	for _, part := range res.Splits {
+		lines = append(lines, fmt.Sprintf("→ split %.2f to %s", part.Amount, part.Category.Name))
+	}
+	if out.Payee != "" && out.Payee != out.Description {
+		lines = append(lines, "→ payee "+out.Payee)
+	}
+	if out.Tags != "" {
+		lines = append(lines, "→ tags "+out.Tags)
+	}
+	if out.IsTransfer {
+		lines = append(lines, "→ transfer")
+	}
+	if res.Skip {
+		lines = append(lines, "→ skipped on import")
+	}
+
+	names := make([]string, 0, len(res.Matched))
+	for _, id := range res.Matched {
+		for _, rule := range list {
+			if rule.ID == id {
+				names = append(names, ruleLabel(rule))
+			}
+		}
+	}
+	lines = append(lines, "Matched: "+strings.Join(names, ", "))
+	return strings.Join(lines, "\n")
+}
+
+// ruleLabel is the rule name, or its priority when it has none
+func ruleLabel(rule models.CategoryRule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return fmt.Sprintf("#%d", rule.Priority)
+}
+
+// ruleSummary shows the conditions and the actions of a rule on one line
+func ruleSummary(rule models.CategoryRule) (string, string) {
+	var when []string
+	if rule.Pattern != "" {
+		when = append(when, "/"+rule.Pattern+"/")
+	}
+	if len(rule.Conditions) > 0 {
+		conditions := rules.FormatConditions(rule.Conditions)
+		if rule.Match == models.MatchAny && len(rule.Conditions) > 1 {
+			conditions = "any: " + conditions
+		}
+		when = append(when, conditions)
+	}
+
+	var then []string
+	if rule.Category != nil {
+		then = append(then, rule.Category.Name)
+	}
+	if len(rule.Actions) > 0 {
+		then = append(then, rules.FormatActions(rule.Actions))
+	}
+	if rule.Stop {
+		then = append(then, "stop")
+	}
+	return strings.Join(when, " & "), strings.Join(then, "; ")
+}
+
+func (m *RulesModel) View() string {
+	view := "🏷 Categorization Rules\n\n"
+
+	if m.errMsg != "" {
+		view += errorStyle.Render("❌ "+m.errMsg) + "\n\n"
+	} else if m.info != "" {
+		view += greenStyle.Render(m.info) + "\n\n"
+	}
+
+	if len(m.rules) == 0 {
+		view += "No rules yet.\n"
+	} else {
+		view += headerStyle.Render(fmt.Sprintf("  %-4s %-3s %-16s %-40s %s", "Prio", "On", "Name", "When", "Then"))
+		view += "\n"
+		for i, rule := range m.rules {
+			cursor := "  "
+			if i == m.cursor {
+				cursor = "> "
+			}
+			on := "[x]"
+			if !rule.Enabled {
+				on = "[ ]"
+			}
+			when, then := ruleSummary(rule)
+			line := fmt.Sprintf("%s%-4d %-3s %-16s %-40s %s", cursor, rule.Priority, on, rule.Name, when, then)
+			if !rule.Enabled {
+				line = orangeStyle.Render(line)
+			}
+			view += line + "\n"
+		}
+	}
+
+	if m.editing {
+		title := "Add rule"
+		if m.editID != 0 {
+			title = "Edit rule"
+		}
+		view += fmt.Sprintf("\n✏️ %s\n\n", title)
+		for i, input := range m.editInputs {
+			view += renderInput(input, i == m.editFocus) + "\n"
+		}
+		view += "\n[Tab/Shift+Tab] Next • [Enter] Save • [Esc] Cancel"
+		return view
+	}
+
+	if m.report != nil {
+		view += "\n📋 Rules against the stored transactions\n\n" + m.reportView()
+		view += "\n[Esc] Done"
+		return view
+	}
+
+	if m.testing {
+		view += "\n🧪 Rule test bench\n\n"
+		for i, input := range m.testInputs {
+			view += renderInput(input, i == m.testFocus) + "\n"
+		}
+		if m.testOutput != "" {
+			view += m.testOutput + "\n"
+		}
+		view += "\n[Tab] Next • [Esc] Done"
+		return view
+	}
+
+	if m.deleting {
+		view += fmt.Sprintf("\nDelete rule %s? [y] Yes • [n] No", ruleTitle(m.rules[m.cursor]))
+		return view
+	}
+
+	view += "\n[↑/↓] Move • [Shift+↑/↓] Reorder • [a] Add • [e] Edit • [Space] Enable/Disable • [x] Delete • [t] Test bench • [p] History report • [c] Re-categorize history • [b] Back"
+	return view
+}
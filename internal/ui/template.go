@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"peronal_finance_cli_manager/internal/budget"
+	"peronal_finance_cli_manager/internal/db"
+	"peronal_finance_cli_manager/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ExpectedIncomeModel sets the monthly income that budgets set as a share
+// of income are taken from
+type ExpectedIncomeModel struct {
+	input  textinput.Model
+	errMsg string
+
+	// Done is set once the form is closed; Info tells what was set
+	Done bool
+	Info string
+}
+
+func NewExpectedIncomeModel() *ExpectedIncomeModel {
+	input := textinput.New()
+	input.Placeholder = "Expected monthly income (empty = the income received)"
+	if expected, err := db.GetExpectedIncome(); err == nil && expected > 0 {
+		input.SetValue(fmt.Sprintf("%.2f", expected))
+	}
+	input.Focus()
+	return &ExpectedIncomeModel{input: input}
+}
+
+func (m *ExpectedIncomeModel) Update(msg tea.Msg) (*ExpectedIncomeModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.Done = true
+			return m, nil
+
+		case tea.KeyEnter:
+			m.save()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *ExpectedIncomeModel) save() {
+	var amount float64
+	if value := strings.TrimSpace(m.input.Value()); value != "" {
+		var err error
+		if amount, err = strconv.ParseFloat(value, 32); err != nil {
+			m.errMsg = "Invalid amount"
+			return
+		}
+	}
+	if err := db.SetExpectedIncome(float32(amount)); err != nil {
+		m.errMsg = err.Error()
+		return
+	}
+
+	m.Done = true
+	m.Info = "Shares of income follow the income received"
+	if amount > 0 {
+		m.Info = fmt.Sprintf("Shares of income follow an expected %.2f a month", amount)
+	}
+}
+
+func (m *ExpectedIncomeModel) View() string {
+	view := "\n💵 Expected income\n\n" + renderInput(m.input, true) + "\n"
+	if m.errMsg != "" {
+		view += "\n" + errorStyle.Render("❌ "+m.errMsg) + "\n"
+	}
+	return view + "\n[Enter] Save • [Esc] Cancel"
+}
+
+// templateView compares the template groups with their share of income
+// for the month shown in the budget overview, flagging the groups over
+// their share, and lists the expense categories outside the groups
+func templateView(offset int, stats []models.BudgetStats) string {
+	template, err := db.GetBudgetTemplate()
+	if err != nil {
+		return errorStyle.Render("❌ Failed to load the budget template: "+err.Error()) + "\n"
+	}
+	if len(template.Shares) == 0 {
+		return ""
+	}
+	groups, err := db.GetGroupStats(time.Now(), offset)
+	if err != nil {
+		return errorStyle.Render("❌ Failed to load the template groups: "+err.Error()) + "\n"
+	}
+
+	month := budget.Containing(budget.Monthly, time.Time{}, time.Now()).Shift(offset)
+	view := fmt.Sprintf("\n📐 %s template (%s, income %.0f)\n\n", template.Name, month.Label(), groups[0].Income)
+	for _, g := range groups {
+		share := "-"
+		if percent := g.Percent(); !math.IsInf(percent, 1) {
+			share = fmt.Sprintf("%.0f%%", percent)
+		}
+		line := fmt.Sprintf("%-8s %7.0f  %5s of income / %3.0f%%", g.Group, g.Spent, share, g.Share)
+		switch {
+		case g.Over():
+			view += redStyle.Render(line+"  ⚠️ over its share") + "\n"
+		case g.Minimum:
+			view += greenStyle.Render(line+" at least") + "\n"
+		default:
+			view += greenStyle.Render(line) + "\n"
+		}
+	}
+
+	var outside []string
+	for _, s := range stats {
+		inTemplate := false
+		for _, share := range template.Shares {
+			inTemplate = inTemplate || s.Group == share.Group
+		}
+		if s.Kind != models.KindIncome && !inTemplate {
+			outside = append(outside, s.CategoryName)
+		}
+	}
+	if len(outside) > 0 {
+		view += "Not in a group: " + strings.Join(outside, ", ") + "\n"
+	}
+	return view
+}